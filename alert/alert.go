@@ -1,6 +1,14 @@
 package alert
 
 import (
+	"context"
+	"encoding/json"
+	"errors"
+	"math"
+	"sort"
+	"sync"
+	"time"
+
 	"github.com/stevenwilkin/treasury/state"
 
 	log "github.com/sirupsen/logrus"
@@ -8,91 +16,463 @@ import (
 
 type Alert interface {
 	Check() bool
+	Cleared(margin float64) bool
 	Active() bool
 	Priority() bool
+	Activate()
 	Deactivate()
 	Description() string
 	Message() string
+	Value() float64
+}
+
+type PersistentAlert interface {
+	Alert
+	Type() string
+	Params() interface{}
+}
+
+type entry struct {
+	id         int
+	created    time.Time
+	triggered  time.Time
+	snoozed    time.Time
+	value      float64
+	persistent bool
+	repeat     *Repeat
+	fired      bool
+}
+
+func (e *entry) setValue(v float64) {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return
+	}
+
+	e.value = v
+}
+
+type Repeat struct {
+	Hysteresis float64
+	Cooldown   time.Duration
+}
+
+type Info struct {
+	Id          int
+	Active      bool
+	Description string
+	Created     time.Time
+	Triggered   time.Time
+	Snoozed     time.Time
+	Value       float64
+	Standing    bool
+	Repeat      *Repeat
+	Escalating  bool
+}
+
+type notification struct {
+	id          int
+	description string
+	message     string
+	priority    bool
+}
+
+func (n *notification) AlertId() int {
+	return n.id
+}
+
+func (n *notification) Check() bool {
+	return false
+}
+
+func (n *notification) Cleared(_ float64) bool {
+	return true
+}
+
+func (n *notification) Active() bool {
+	return false
+}
+
+func (n *notification) Activate() {}
+
+func (n *notification) Deactivate() {}
+
+func (n *notification) Value() float64 {
+	return 0
+}
+
+func (n *notification) Description() string {
+	return n.description
+}
+
+func (n *notification) Message() string {
+	return n.message
+}
+
+func (n *notification) Priority() bool {
+	return n.priority
+}
+
+func AlertId(a Alert) int {
+	if n, ok := a.(interface{ AlertId() int }); ok {
+		return n.AlertId()
+	}
+
+	return 0
 }
 
+var ErrNotFound = errors.New("Alert not found")
+
+const queueSize = 100
+
 type Alerter struct {
+	mu       sync.Mutex
 	state    *state.State
 	notifier Notifier
-	alerts   map[Alert]bool
+	alerts   map[Alert]*entry
+	nextId   int
+	queue    chan *notification
+	stopped  bool
 }
 
-func (a *Alerter) Alerts() []Alert {
-	alerts := make([]Alert, len(a.alerts))
-	i := 0
+func (a *Alerter) List() []Info {
+	a.mu.Lock()
+	defer a.mu.Unlock()
 
-	for alert := range a.alerts {
-		alerts[i] = alert
-		i++
+	infos := []Info{}
+	ack, _ := a.notifier.(Acknowledger)
+
+	for alert, e := range a.alerts {
+		infos = append(infos, Info{
+			Id:          e.id,
+			Active:      alert.Active(),
+			Description: alert.Description(),
+			Created:     e.created,
+			Triggered:   e.triggered,
+			Snoozed:     e.snoozed,
+			Value:       e.value,
+			Standing:    !e.persistent,
+			Repeat:      e.repeat,
+			Escalating:  ack != nil && ack.Escalating(e.id)})
 	}
 
-	return alerts
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Id < infos[j].Id })
+
+	return infos
 }
 
 func (a *Alerter) ClearAlerts() {
-	a.alerts = map[Alert]bool{}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.alerts = map[Alert]*entry{}
+}
+
+func (a *Alerter) find(id int) (Alert, *entry, error) {
+	for alert, e := range a.alerts {
+		if e.id == id {
+			return alert, e, nil
+		}
+	}
+
+	return nil, nil, ErrNotFound
+}
+
+func (a *Alerter) Delete(id int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	alert, _, err := a.find(id)
+	if err != nil {
+		return err
+	}
+
+	delete(a.alerts, alert)
+	return nil
+}
+
+func (a *Alerter) Snooze(id int, d time.Duration) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	_, e, err := a.find(id)
+	if err != nil {
+		return err
+	}
+
+	e.snoozed = time.Now().Add(d)
+	return nil
+}
+
+func (a *Alerter) Rearm(id int) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	alert, e, err := a.find(id)
+	if err != nil {
+		return err
+	}
+
+	e.snoozed = time.Time{}
+	e.fired = false
+	alert.Activate()
+	return nil
+}
+
+func (a *Alerter) Ack(id int) error {
+	if ack, ok := a.notifier.(Acknowledger); ok && ack.Ack(id) {
+		return nil
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if _, _, err := a.find(id); err != nil {
+		return err
+	}
+
+	return ErrNotEscalating
+}
+
+func (a *Alerter) SetRepeat(id int, r *Repeat) error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	_, e, err := a.find(id)
+	if err != nil {
+		return err
+	}
+
+	e.repeat = r
+	e.fired = false
+	return nil
+}
+
+func (a *Alerter) add(alert Alert, persistent bool) int {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	a.nextId++
+	a.alerts[alert] = &entry{
+		id:         a.nextId,
+		created:    time.Now(),
+		persistent: persistent}
+
+	return a.nextId
+}
+
+func (a *Alerter) AddAlert(alert Alert) int {
+	return a.add(alert, true)
+}
+
+func (a *Alerter) AddStandingAlert(alert Alert) int {
+	return a.add(alert, false)
 }
 
-func (a *Alerter) AddAlert(alert Alert) {
-	a.alerts[alert] = true
+func (a *Alerter) enqueue(n *notification) bool {
+	if a.stopped {
+		return false
+	}
+
+	select {
+	case a.queue <- n:
+		return true
+	default:
+		log.WithField("id", n.id).Warn("Notification queue full")
+		return false
+	}
 }
 
 func (a *Alerter) CheckAlerts() {
-	for alert := range a.alerts {
-		if !alert.Active() {
+	now := time.Now()
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	for alert, e := range a.alerts {
+		if !alert.Active() || now.Before(e.snoozed) {
 			continue
 		}
-		if alert.Check() {
-			if err := a.notifier.Notify(alert); err != nil {
-				log.Error(err.Error())
-			}
+
+		if e.fired && e.repeat == nil {
+			e.fired = false
+		}
+
+		if e.fired {
+			e.fired = !alert.Cleared(e.repeat.Hysteresis)
+			e.setValue(alert.Value())
+			continue
+		}
+
+		triggered := alert.Check()
+		e.setValue(alert.Value())
+
+		if !triggered {
+			continue
+		}
+
+		if e.repeat != nil && now.Sub(e.triggered) < e.repeat.Cooldown {
+			continue
+		}
+
+		queued := a.enqueue(&notification{
+			id:          e.id,
+			description: alert.Description(),
+			message:     alert.Message(),
+			priority:    alert.Priority()})
+		if !queued {
+			continue
+		}
+
+		e.triggered = now
+
+		if e.repeat != nil {
+			e.fired = true
+		} else {
 			alert.Deactivate()
 		}
 	}
 }
 
+func (a *Alerter) send(n *notification) {
+	if err := a.notifier.Notify(n); err != nil {
+		log.Error(err.Error())
+	}
+}
+
+func (a *Alerter) Run(ctx context.Context) {
+	for {
+		select {
+		case n := <-a.queue:
+			a.send(n)
+		case <-ctx.Done():
+			a.mu.Lock()
+			a.stopped = true
+			a.mu.Unlock()
+
+			for len(a.queue) > 0 {
+				a.send(<-a.queue)
+			}
+			return
+		}
+	}
+}
+
 func (a *Alerter) Persist() {
-	var (
-		fundingAlert bool
-		priceAlerts  []float64
-	)
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	specs := []state.AlertSpec{}
+
+	for alert, e := range a.alerts {
+		if !e.persistent {
+			continue
+		}
 
-	for alert := range a.alerts {
-		if !alert.Active() {
+		pa, ok := alert.(PersistentAlert)
+		if !ok {
 			continue
 		}
 
-		switch alert.(type) {
-		case *FundingAlert:
-			fundingAlert = true
-		case *PriceAlert:
-			priceAlerts = append(priceAlerts, alert.(*PriceAlert).price)
+		params, err := json.Marshal(pa.Params())
+		if err != nil {
+			log.WithField("type", pa.Type()).Error(err.Error())
+			continue
+		}
+
+		spec := state.AlertSpec{
+			Id:        e.id,
+			Type:      pa.Type(),
+			Params:    params,
+			Created:   e.created,
+			Triggered: e.triggered,
+			Snoozed:   e.snoozed,
+			Value:     e.value,
+			Fired:     e.fired,
+			Active:    alert.Active()}
+
+		if e.repeat != nil {
+			spec.Repeat = true
+			spec.Hysteresis = e.repeat.Hysteresis
+			spec.Cooldown = e.repeat.Cooldown
 		}
+
+		specs = append(specs, spec)
 	}
 
-	a.state.SetFundingAlert(fundingAlert)
-	a.state.SetPriceAlerts(priceAlerts)
+	sort.Slice(specs, func(i, j int) bool { return specs[i].Id < specs[j].Id })
+
+	a.state.SetAlerts(specs)
 }
 
-func (a *Alerter) Retrieve() {
-	if a.state.GetFundingAlert() {
+func (a *Alerter) restore(spec state.AlertSpec) error {
+	alert, err := Build(a.state, spec.Type, spec.Params)
+	if err != nil {
+		return err
+	}
+
+	if !spec.Active {
+		alert.Deactivate()
+	}
+
+	e := &entry{
+		id:         spec.Id,
+		created:    spec.Created,
+		triggered:  spec.Triggered,
+		snoozed:    spec.Snoozed,
+		value:      spec.Value,
+		persistent: true}
+
+	if spec.Repeat {
+		e.repeat = &Repeat{
+			Hysteresis: spec.Hysteresis,
+			Cooldown:   spec.Cooldown}
+		e.fired = spec.Fired
+	}
+
+	a.alerts[alert] = e
+
+	if spec.Id > a.nextId {
+		a.nextId = spec.Id
+	}
+
+	return nil
+}
+
+func (a *Alerter) retrieveLegacy() {
+	funding, prices := a.state.TakeLegacyAlerts()
+
+	if funding {
 		a.AddFundingAlert()
 	}
 
-	if priceAlerts := a.state.GetPriceAlerts(); len(priceAlerts) > 0 {
-		for _, price := range priceAlerts {
-			a.AddPriceAlert(price)
+	for _, pa := range prices {
+		d, err := ParseDirection(pa.Direction)
+		if err != nil {
+			d = Crosses
 		}
+		a.AddPriceAlert(pa.Symbol, d, pa.Price, pa.Expires, pa.Note)
 	}
 }
 
+func (a *Alerter) Retrieve() {
+	a.mu.Lock()
+	for _, spec := range a.state.GetAlerts() {
+		if err := a.restore(spec); err != nil {
+			log.WithFields(log.Fields{
+				"id":   spec.Id,
+				"type": spec.Type,
+			}).Warn(err.Error())
+		}
+	}
+	a.mu.Unlock()
+
+	a.retrieveLegacy()
+}
+
 func NewAlerter(state *state.State, notifier Notifier) *Alerter {
 	return &Alerter{
 		state:    state,
 		notifier: notifier,
-		alerts:   map[Alert]bool{}}
+		alerts:   map[Alert]*entry{},
+		queue:    make(chan *notification, queueSize)}
 }
@@ -1,9 +1,15 @@
 package alert
 
 import (
+	"context"
+	"encoding/json"
+	"math"
+	"sync"
 	"testing"
+	"time"
 
 	"github.com/stevenwilkin/treasury/state"
+	"github.com/stevenwilkin/treasury/symbol"
 )
 
 type TestAlert struct {
@@ -13,30 +19,57 @@ type TestAlert struct {
 	checked   bool
 }
 
-func (a *TestAlert) Check() bool         { a.checked = true; return a.triggered }
-func (a *TestAlert) Active() bool        { return a.active }
-func (a *TestAlert) Priority() bool      { return a.priority }
-func (a *TestAlert) Deactivate()         { a.active = false }
-func (a *TestAlert) Description() string { return "" }
-func (a *TestAlert) Message() string     { return "" }
+func (a *TestAlert) Check() bool          { a.checked = true; return a.triggered }
+func (a *TestAlert) Cleared(float64) bool { return !a.triggered }
+func (a *TestAlert) Active() bool         { return a.active }
+func (a *TestAlert) Priority() bool       { return a.priority }
+func (a *TestAlert) Activate()            { a.active = true }
+func (a *TestAlert) Deactivate()          { a.active = false }
+func (a *TestAlert) Description() string  { return "" }
+func (a *TestAlert) Message() string      { return "" }
+func (a *TestAlert) Value() float64       { return 1 }
 
 var _ Alert = &TestAlert{}
 
 type TestNotifier struct {
 	alert Alert
+	id    int
 }
 
-func (n *TestNotifier) Notify(a Alert) error { n.alert = a; return nil }
+func (n *TestNotifier) Notify(a Alert) error {
+	n.alert = a
+	n.id = AlertId(a)
+	return nil
+}
 
 var _ Notifier = &TestNotifier{}
 
+type BlockingNotifier struct {
+	release chan struct{}
+	sent    chan string
+}
+
+func (n *BlockingNotifier) Notify(a Alert) error {
+	<-n.release
+	n.sent <- a.Message()
+	return nil
+}
+
+var _ Notifier = &BlockingNotifier{}
+
+func flush(a *Alerter) {
+	for len(a.queue) > 0 {
+		a.send(<-a.queue)
+	}
+}
+
 func TestAlerts(t *testing.T) {
 	alerter := NewAlerter(state.NewState(), &TestNotifier{})
 	alert := &TestAlert{}
 	alerter.AddAlert(alert)
 
-	if len(alerter.Alerts()) != 1 {
-		t.Errorf("Should return 1 alert, got %d", len(alerter.Alerts()))
+	if len(alerter.List()) != 1 {
+		t.Errorf("Should return 1 alert, got %d", len(alerter.List()))
 	}
 }
 
@@ -46,8 +79,8 @@ func TestClearAlerts(t *testing.T) {
 	alerter.AddAlert(alert)
 	alerter.ClearAlerts()
 
-	if len(alerter.Alerts()) != 0 {
-		t.Errorf("Should return 0 alerts, got %d", len(alerter.Alerts()))
+	if len(alerter.List()) != 0 {
+		t.Errorf("Should return 0 alerts, got %d", len(alerter.List()))
 	}
 }
 
@@ -80,10 +113,11 @@ func TestNotifiesTriggeredAlert(t *testing.T) {
 	alerter := NewAlerter(state.NewState(), notifier)
 	alert := &TestAlert{active: true, triggered: true}
 
-	alerter.AddAlert(alert)
+	id := alerter.AddAlert(alert)
 	alerter.CheckAlerts()
+	flush(alerter)
 
-	if notifier.alert != alert {
+	if notifier.id != id {
 		t.Error("Should notify triggered alert")
 	}
 }
@@ -100,127 +134,532 @@ func TestDeactivatesTriggeredAlert(t *testing.T) {
 	}
 }
 
-func TestDoesNotPersistInactiveAlerts(t *testing.T) {
+func TestPersistsInactiveAlerts(t *testing.T) {
 	s := state.NewState()
 	alerter := NewAlerter(s, &TestNotifier{})
-	alerter.AddAlert(&PriceAlert{})
-	alerter.AddAlert(&FundingAlert{})
+	alert := NewFundingAlert(s)
+	alert.Deactivate()
+	alerter.AddAlert(alert)
 
 	alerter.Persist()
 
-	if s.GetFundingAlert() {
-		t.Error("Should not have funding alert")
+	specs := s.GetAlerts()
+	if len(specs) != 1 {
+		t.Fatal("Should persist inactive alert")
 	}
 
-	if len(s.GetPriceAlerts()) != 0 {
-		t.Error("Should not have price alerts")
+	if specs[0].Active {
+		t.Error("Should persist alert as inactive")
 	}
 }
 
-func TestPersistsFundingAlert(t *testing.T) {
+func TestPersistsAlertSpec(t *testing.T) {
 	s := state.NewState()
 	alerter := NewAlerter(s, &TestNotifier{})
-	alerter.AddAlert(&FundingAlert{active: true})
+	alerter.AddLeverageAlert(4)
 
 	alerter.Persist()
 
-	if !s.GetFundingAlert() {
-		t.Error("Should have funding alert")
+	specs := s.GetAlerts()
+	if len(specs) != 1 {
+		t.Fatal("Should persist alert")
+	}
+
+	spec := specs[0]
+	if spec.Id != 1 || spec.Type != "leverage" || !spec.Active ||
+		spec.Created.IsZero() || string(spec.Params) != `{"Threshold":4}` {
+		t.Errorf("Unexpected alert spec %+v", spec)
 	}
 }
 
 func TestPersistsPriceAlerts(t *testing.T) {
 	s := state.NewState()
 	alerter := NewAlerter(s, &TestNotifier{})
-	alerter.AddAlert(&PriceAlert{active: true, price: 10000})
-	alerter.AddAlert(&PriceAlert{active: true, price: 20000})
+	alerter.AddPriceAlert(symbol.BTCUSDT, Crosses, 10000, time.Time{}, "")
+	alerter.AddPriceAlert(symbol.USDTHB, Below, 20000, time.Time{}, "note")
 
 	alerter.Persist()
 
-	if len(s.GetPriceAlerts()) != 2 {
+	specs := s.GetAlerts()
+	if len(specs) != 2 {
 		t.Fatal("Should have price alerts")
 	}
 
-	var hasFirstAlert, hasSecondAlert bool
-
-	for _, a := range s.GetPriceAlerts() {
-		if a == 10000 {
-			hasFirstAlert = true
-		} else if a == 20000 {
-			hasSecondAlert = true
-		}
+	var p priceParams
+	if err := json.Unmarshal(specs[1].Params, &p); err != nil {
+		t.Fatal(err)
 	}
 
-	if !hasFirstAlert || !hasSecondAlert {
-		t.Error("Should persist details of price alerts")
+	if p.Symbol != "USDTHB" || p.Direction != "below" || p.Price != 20000 ||
+		p.Note != "note" {
+		t.Errorf("Should persist details of price alerts, got %+v", p)
 	}
 }
 
-func TestPersistClearsPreviousAlerts(t *testing.T) {
+func TestDoesNotPersistStandingAlerts(t *testing.T) {
 	s := state.NewState()
 	alerter := NewAlerter(s, &TestNotifier{})
-
-	s.SetFundingAlert(true)
-	s.SetPriceAlerts([]float64{10000})
+	alerter.AddStandingAlert(NewPriceAlert(s, symbol.BTCUSDT, Above, 10000))
 
 	alerter.Persist()
 
-	if s.GetFundingAlert() {
-		t.Error("Should not have funding alert")
+	if len(s.GetAlerts()) != 0 {
+		t.Error("Should not persist standing alerts")
 	}
 
-	if len(s.GetPriceAlerts()) != 0 {
-		t.Error("Should not have price alerts")
+	if len(alerter.List()) != 1 {
+		t.Error("Should have standing alert")
 	}
 }
 
-func TestRetrieve(t *testing.T) {
+func TestDoesNotPersistUnserialisableAlerts(t *testing.T) {
 	s := state.NewState()
 	alerter := NewAlerter(s, &TestNotifier{})
+	alerter.AddAlert(&TestAlert{active: true})
 
-	s.SetFundingAlert(true)
-	s.SetPriceAlerts([]float64{10000, 20000})
+	alerter.Persist()
 
-	alerter.Retrieve()
-	alerts := alerter.Alerts()
+	if len(s.GetAlerts()) != 0 {
+		t.Error("Should not persist alerts without a spec")
+	}
+}
+
+func TestPersistClearsPreviousAlerts(t *testing.T) {
+	s := state.NewState()
+	alerter := NewAlerter(s, &TestNotifier{})
+
+	s.SetAlerts([]state.AlertSpec{{Id: 1, Type: "funding"}})
+
+	alerter.Persist()
 
-	if len(alerts) != 3 {
-		t.Fatal("Should have alerts")
+	if len(s.GetAlerts()) != 0 {
+		t.Error("Should not have alerts")
 	}
 }
 
-func TestRetrieveFundingAlert(t *testing.T) {
+func TestRetrieve(t *testing.T) {
 	s := state.NewState()
 	alerter := NewAlerter(s, &TestNotifier{})
+	alerter.AddFundingAlert()
+	alerter.AddLeverageAlert(4)
+	alerter.AddMoveAlert(symbol.BTCUSDT, Up, 5, time.Hour)
+	alerter.AddExposureAlert(0.5, true)
+	alerter.AddPremiumAlert(THBPremium, math.Inf(-1), 3)
+	alerter.AddPortfolioAlert(PnlPercentageMetric, false, Below, -5)
+	alerter.AddPriceAlert(symbol.USDTHB, Below, 30, time.Now().Add(time.Hour), "note")
+	alerter.Persist()
+
+	expected := map[string]bool{}
+	for _, alert := range alerter.List() {
+		expected[alert.Description] = true
+	}
+
+	retrieved := NewAlerter(s, &TestNotifier{})
+	retrieved.Retrieve()
+	alerts := retrieved.List()
+
+	if len(alerts) != len(expected) {
+		t.Fatalf("Should have %d alerts, got %d", len(expected), len(alerts))
+	}
 
-	s.SetFundingAlert(true)
+	for _, alert := range alerts {
+		if !expected[alert.Description] {
+			t.Errorf("Unexpected alert '%s'", alert.Description)
+		}
+	}
+}
 
+func TestRetrieveInactiveAlert(t *testing.T) {
+	s := state.NewState()
+	s.SetAlerts([]state.AlertSpec{
+		{Id: 3, Type: "funding", Params: []byte("{}"), Active: false}})
+
+	alerter := NewAlerter(s, &TestNotifier{})
 	alerter.Retrieve()
-	alerts := alerter.Alerts()
+	alerts := alerter.List()
 
 	if len(alerts) != 1 {
 		t.Fatal("Should have alert")
 	}
 
-	if _, ok := alerts[0].(*FundingAlert); !ok {
-		t.Error("Should have a funding alert")
+	if alerts[0].Active {
+		t.Error("Should retrieve alert as inactive")
+	}
+
+	alerter.AddLeverageAlert(4)
+	alerter.Persist()
+
+	if specs := s.GetAlerts(); len(specs) != 2 || specs[1].Id != 4 {
+		t.Error("Should not reuse retrieved ids")
 	}
 }
 
-func TestRetrievePriceAlerts(t *testing.T) {
+func TestRetrieveUnknownAlertType(t *testing.T) {
 	s := state.NewState()
+	s.SetAlerts([]state.AlertSpec{{Id: 1, Type: "fake", Active: true}})
+
 	alerter := NewAlerter(s, &TestNotifier{})
+	alerter.Retrieve()
+
+	if len(alerter.List()) != 0 {
+		t.Error("Should skip unknown alert types")
+	}
+}
 
-	s.SetPriceAlerts([]float64{10000})
+func TestRetrieveLegacyAlerts(t *testing.T) {
+	var s state.State
+	b := []byte(`{"FundingAlert": true, "PriceAlerts": [10000]}`)
+	if err := json.Unmarshal(b, &s); err != nil {
+		t.Fatal(err)
+	}
 
+	alerter := NewAlerter(&s, &TestNotifier{})
 	alerter.Retrieve()
-	alerts := alerter.Alerts()
+	alerts := alerter.List()
 
-	if len(alerts) != 1 {
-		t.Fatal("Should have alert")
+	if len(alerts) != 2 {
+		t.Fatalf("Should migrate legacy alerts, got %d", len(alerts))
+	}
+
+	alerter.Persist()
+
+	if len(s.GetAlerts()) != 2 {
+		t.Error("Should persist migrated alerts")
+	}
+
+	if funding, prices := s.TakeLegacyAlerts(); funding || len(prices) != 0 {
+		t.Error("Should clear legacy alerts")
+	}
+}
+
+func TestAlertIds(t *testing.T) {
+	alerter := NewAlerter(state.NewState(), &TestNotifier{})
+
+	first := alerter.AddAlert(&TestAlert{})
+	second := alerter.AddLeverageAlert(4)
+
+	if first != 1 || second != 2 {
+		t.Errorf("Expected ids 1 and 2, got: %d and %d", first, second)
+	}
+
+	for i, info := range alerter.List() {
+		if info.Id != i+1 {
+			t.Error("Should list alerts sorted by id")
+		}
+	}
+}
+
+func TestDelete(t *testing.T) {
+	alerter := NewAlerter(state.NewState(), &TestNotifier{})
+	id := alerter.AddAlert(&TestAlert{})
+	alerter.AddAlert(&TestAlert{})
+
+	if err := alerter.Delete(id); err != nil {
+		t.Fatal(err)
+	}
+
+	if alerts := alerter.List(); len(alerts) != 1 || alerts[0].Id == id {
+		t.Error("Should delete alert")
+	}
+
+	if err := alerter.Delete(id); err != ErrNotFound {
+		t.Error("Should not find deleted alert")
+	}
+}
+
+func TestSnooze(t *testing.T) {
+	alerter := NewAlerter(state.NewState(), &TestNotifier{})
+	alert := &TestAlert{active: true, triggered: true}
+	id := alerter.AddAlert(alert)
+
+	if err := alerter.Snooze(id, time.Hour); err != nil {
+		t.Fatal(err)
+	}
+
+	alerter.CheckAlerts()
+
+	if alert.checked {
+		t.Error("Should not check snoozed alert")
+	}
+
+	if err := alerter.Snooze(id, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	alerter.CheckAlerts()
+
+	if !alert.checked {
+		t.Error("Should check alert once snooze has passed")
+	}
+}
+
+func TestRearm(t *testing.T) {
+	alerter := NewAlerter(state.NewState(), &TestNotifier{})
+	alert := &TestAlert{active: true, triggered: true}
+	id := alerter.AddAlert(alert)
+
+	alerter.CheckAlerts()
+
+	info := alerter.List()[0]
+	if alert.active || info.Triggered.IsZero() || info.Value != 1 {
+		t.Fatal("Should record trigger time and value")
+	}
+
+	if err := alerter.Rearm(id); err != nil {
+		t.Fatal(err)
+	}
+
+	if !alert.active {
+		t.Error("Should re-arm alert")
+	}
+
+	if err := alerter.Rearm(id + 1); err != ErrNotFound {
+		t.Error("Should not find unknown alert")
+	}
+}
+
+func TestPersistTrigger(t *testing.T) {
+	s := state.NewState()
+	s.SetFundingRate(-0.01)
+
+	alerter := NewAlerter(s, &TestNotifier{})
+	id := alerter.AddFundingAlert()
+	alerter.CheckAlerts()
+	alerter.Snooze(id, time.Hour)
+	alerter.Persist()
+
+	retrieved := NewAlerter(s, &TestNotifier{})
+	retrieved.Retrieve()
+	info := retrieved.List()[0]
+
+	if info.Id != id || info.Triggered.IsZero() || info.Snoozed.IsZero() {
+		t.Error("Should retrieve trigger and snooze times")
+	}
+
+	if info.Value != -0.01 {
+		t.Errorf("Expected: %f, got: %f", -0.01, info.Value)
+	}
+}
+
+func TestRepeatingAlert(t *testing.T) {
+	notifier := &TestNotifier{}
+	alerter := NewAlerter(state.NewState(), notifier)
+	alert := &TestAlert{active: true, triggered: true}
+	id := alerter.AddAlert(alert)
+	alerter.SetRepeat(id, &Repeat{})
+
+	alerter.CheckAlerts()
+	flush(alerter)
+
+	if notifier.id != id || !alert.active {
+		t.Fatal("Should notify and remain active")
+	}
+
+	notifier.alert = nil
+	alerter.CheckAlerts()
+	flush(alerter)
+
+	if notifier.alert != nil {
+		t.Error("Should not notify again until cleared")
+	}
+
+	alert.triggered = false
+	alerter.CheckAlerts()
+	alert.triggered = true
+	alerter.CheckAlerts()
+	flush(alerter)
+
+	if notifier.id != id {
+		t.Error("Should notify again once cleared")
+	}
+}
+
+func TestRepeatingAlertCooldown(t *testing.T) {
+	notifier := &TestNotifier{}
+	alerter := NewAlerter(state.NewState(), notifier)
+	alert := &TestAlert{active: true, triggered: true}
+	id := alerter.AddAlert(alert)
+	alerter.SetRepeat(id, &Repeat{Cooldown: time.Hour})
+
+	alerter.CheckAlerts()
+	flush(alerter)
+	notifier.alert = nil
+
+	alert.triggered = false
+	alerter.CheckAlerts()
+	alert.triggered = true
+	alerter.CheckAlerts()
+	flush(alerter)
+
+	if notifier.alert != nil {
+		t.Error("Should not notify within cooldown")
+	}
+}
+
+func TestPersistRepeat(t *testing.T) {
+	s := state.NewState()
+	s.SetFundingRate(-0.01)
+
+	alerter := NewAlerter(s, &TestNotifier{})
+	id := alerter.AddFundingAlert()
+	alerter.SetRepeat(id, &Repeat{Hysteresis: 0.001, Cooldown: time.Hour})
+	alerter.CheckAlerts()
+	alerter.Persist()
+
+	retrieved := NewAlerter(s, &TestNotifier{})
+	retrieved.Retrieve()
+	info := retrieved.List()[0]
+
+	if info.Repeat == nil || info.Repeat.Hysteresis != 0.001 || info.Repeat.Cooldown != time.Hour {
+		t.Fatal("Should retrieve repeat settings")
 	}
 
-	if alert, _ := alerts[0].(*PriceAlert); alert.price != 10000 {
-		t.Error("Should have a price alert")
+	if !info.Active {
+		t.Error("Repeating alert should remain active")
 	}
 }
+
+func TestRunSendsNotifications(t *testing.T) {
+	notifier := &BlockingNotifier{
+		release: make(chan struct{}),
+		sent:    make(chan string, 1)}
+	s := state.NewState()
+	s.SetFundingRate(-0.01)
+
+	alerter := NewAlerter(s, notifier)
+	alerter.AddFundingAlert()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go alerter.Run(ctx)
+
+	done := make(chan struct{})
+	go func() {
+		alerter.CheckAlerts()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Should not block checks on a slow notifier")
+	}
+
+	s.SetFundingRate(0.01)
+	close(notifier.release)
+
+	select {
+	case message := <-notifier.sent:
+		if message != "Funding: -1.000000%" {
+			t.Errorf("Expected message at time of trigger, got: '%s'", message)
+		}
+	case <-time.After(time.Second):
+		t.Error("Should send notification")
+	}
+}
+
+func TestFiredAlertWithoutRepeat(t *testing.T) {
+	alerter := NewAlerter(state.NewState(), &TestNotifier{})
+	alert := &TestAlert{active: true, triggered: true}
+
+	id := alerter.AddAlert(alert)
+	alerter.SetRepeat(id, &Repeat{})
+	alerter.CheckAlerts()
+	flush(alerter)
+
+	_, e, _ := alerter.find(id)
+	e.repeat = nil
+
+	alerter.CheckAlerts()
+
+	if e.fired || alert.active {
+		t.Error("Should check fired alert without a repeat as a one-off alert")
+	}
+
+	alert.active = true
+	alerter.SetRepeat(id, &Repeat{})
+	alerter.CheckAlerts()
+	alerter.SetRepeat(id, nil)
+
+	if e.fired {
+		t.Error("Should clear fired when repeat is removed")
+	}
+}
+
+func TestFullQueueKeepsAlertActive(t *testing.T) {
+	notifier := &TestNotifier{}
+	alerter := NewAlerter(state.NewState(), notifier)
+
+	for i := 0; i < queueSize; i++ {
+		alerter.queue <- &notification{}
+	}
+
+	alert := &TestAlert{active: true, triggered: true}
+	id := alerter.AddAlert(alert)
+	alerter.CheckAlerts()
+
+	if !alert.active {
+		t.Error("Should not deactivate alert that was not queued")
+	}
+
+	flush(alerter)
+	alerter.CheckAlerts()
+	flush(alerter)
+
+	if alert.active || notifier.id != id {
+		t.Error("Should notify alert once queue has drained")
+	}
+}
+
+func TestStoppedAlerterKeepsAlertActive(t *testing.T) {
+	alerter := NewAlerter(state.NewState(), &TestNotifier{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	alerter.Run(ctx)
+
+	alert := &TestAlert{active: true, triggered: true}
+	alerter.AddAlert(alert)
+	alerter.CheckAlerts()
+
+	if !alert.active || len(alerter.queue) != 0 {
+		t.Error("Should not queue notifications once stopped")
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	s := state.NewState()
+	s.SetFundingRate(-0.01)
+	s.SetSymbol(symbol.BTCUSDT, 20000)
+
+	alerter := NewAlerter(s, &TestNotifier{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go alerter.Run(ctx)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			for j := 0; j < 50; j++ {
+				id := alerter.AddFundingAlert()
+				alerter.AddPriceAlert(symbol.BTCUSDT, Above, 10000, time.Time{}, "")
+				alerter.CheckAlerts()
+				alerter.Rearm(id)
+				alerter.Snooze(id, time.Minute)
+				alerter.List()
+				alerter.Persist()
+				alerter.Delete(id)
+
+				if j%10 == 0 {
+					alerter.ClearAlerts()
+				}
+			}
+		}()
+	}
+
+	wg.Wait()
+}
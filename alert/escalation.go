@@ -0,0 +1,152 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+var ErrNotEscalating = errors.New("Alert not escalating")
+
+type Acknowledger interface {
+	Ack(id int) bool
+	Escalating(id int) bool
+}
+
+type Step struct {
+	Notifier Notifier
+	After    time.Duration
+}
+
+type escalation struct {
+	notification *notification
+	step         int
+	due          time.Time
+}
+
+type dispatch struct {
+	notifier     Notifier
+	notification *notification
+}
+
+type Escalator struct {
+	mu      sync.Mutex
+	normal  Notifier
+	steps   []Step
+	pending map[int]*escalation
+}
+
+func (e *Escalator) Notify(a Alert) error {
+	if !a.Priority() || len(e.steps) == 0 {
+		return e.normal.Notify(a)
+	}
+
+	n := &notification{
+		id:          AlertId(a),
+		description: a.Description(),
+		message:     a.Message(),
+		priority:    true}
+
+	now := time.Now()
+
+	e.mu.Lock()
+	e.pending[n.id] = &escalation{
+		notification: n,
+		due:          now.Add(e.steps[0].After)}
+	e.mu.Unlock()
+
+	return e.escalate(now)
+}
+
+func (e *Escalator) due(now time.Time) []dispatch {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	results := []dispatch{}
+
+	for id, esc := range e.pending {
+		for esc.step < len(e.steps) && !esc.due.After(now) {
+			log.WithFields(log.Fields{
+				"id":   id,
+				"step": esc.step + 1,
+			}).Info("Escalating alert")
+
+			results = append(results, dispatch{
+				notifier:     e.steps[esc.step].Notifier,
+				notification: esc.notification})
+
+			esc.step++
+			if esc.step < len(e.steps) {
+				esc.due = esc.due.Add(e.steps[esc.step].After)
+			}
+		}
+
+		if esc.step == len(e.steps) {
+			delete(e.pending, id)
+		}
+	}
+
+	return results
+}
+
+func (e *Escalator) escalate(now time.Time) error {
+	var result error
+
+	for _, d := range e.due(now) {
+		if err := d.notifier.Notify(d.notification); err != nil {
+			log.Error(err.Error())
+			result = err
+		}
+	}
+
+	return result
+}
+
+func (e *Escalator) Ack(id int) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if _, ok := e.pending[id]; !ok {
+		return false
+	}
+
+	delete(e.pending, id)
+	log.WithField("id", id).Info("Alert acknowledged")
+
+	return true
+}
+
+func (e *Escalator) Escalating(id int) bool {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	_, ok := e.pending[id]
+	return ok
+}
+
+func (e *Escalator) Run(ctx context.Context) {
+	ticker := time.NewTicker(time.Second)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			e.escalate(time.Now())
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func NewEscalator(normal Notifier, steps []Step) *Escalator {
+	return &Escalator{
+		normal:  normal,
+		steps:   steps,
+		pending: map[int]*escalation{}}
+}
+
+var _ Notifier = &Escalator{}
+var _ Acknowledger = &Escalator{}
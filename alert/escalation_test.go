@@ -0,0 +1,111 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stevenwilkin/treasury/state"
+)
+
+func testEscalator() (*Escalator, *CountingNotifier, *CountingNotifier, *CountingNotifier) {
+	telegram := &CountingNotifier{}
+	first := &CountingNotifier{}
+	second := &CountingNotifier{}
+
+	e := NewEscalator(telegram, []Step{
+		{Notifier: telegram},
+		{Notifier: first, After: 5 * time.Minute},
+		{Notifier: second, After: 10 * time.Minute}})
+
+	return e, telegram, first, second
+}
+
+func TestEscalatorNormalAlert(t *testing.T) {
+	e, telegram, first, _ := testEscalator()
+	e.Notify(&notification{id: 1})
+
+	if telegram.calls != 1 || first.calls != 0 {
+		t.Error("Should only send normal alert to normal notifier")
+	}
+
+	if e.Escalating(1) {
+		t.Error("Should not escalate normal alert")
+	}
+}
+
+func TestEscalatorEscalates(t *testing.T) {
+	e, telegram, first, second := testEscalator()
+	now := time.Now()
+	e.Notify(&notification{id: 1, priority: true})
+
+	if telegram.calls != 1 || first.calls != 0 {
+		t.Fatal("Should send first step immediately")
+	}
+
+	e.escalate(now.Add(4 * time.Minute))
+	if first.calls != 0 {
+		t.Error("Should not escalate before delay")
+	}
+
+	e.escalate(now.Add(6 * time.Minute))
+	if first.calls != 1 || second.calls != 0 {
+		t.Error("Should escalate to second step")
+	}
+
+	e.escalate(now.Add(16 * time.Minute))
+	if second.calls != 1 {
+		t.Error("Should escalate to third step")
+	}
+
+	if e.Escalating(1) {
+		t.Error("Should finish escalation after last step")
+	}
+}
+
+func TestEscalatorAck(t *testing.T) {
+	e, _, first, _ := testEscalator()
+	e.Notify(&notification{id: 1, priority: true})
+
+	if !e.Escalating(1) {
+		t.Fatal("Should be escalating")
+	}
+
+	if !e.Ack(1) {
+		t.Error("Should acknowledge alert")
+	}
+
+	e.escalate(time.Now().Add(time.Hour))
+	if first.calls != 0 {
+		t.Error("Should not escalate acknowledged alert")
+	}
+
+	if e.Ack(1) {
+		t.Error("Should not acknowledge twice")
+	}
+}
+
+func TestAlerterAck(t *testing.T) {
+	e, _, _, _ := testEscalator()
+	alerter := NewAlerter(state.NewState(), e)
+	id := alerter.AddAlert(&TestAlert{active: true, triggered: true, priority: true})
+	other := alerter.AddAlert(&TestAlert{})
+
+	alerter.CheckAlerts()
+	flush(alerter)
+
+	if !alerter.List()[0].Escalating {
+		t.Fatal("Should list alert as escalating")
+	}
+
+	if err := alerter.Ack(id); err != nil {
+		t.Error(err)
+	}
+
+	if err := alerter.Ack(other); err != ErrNotEscalating {
+		t.Errorf("Expected: %v, got: %v", ErrNotEscalating, err)
+	}
+
+	if err := alerter.Ack(42); err != ErrNotFound {
+		t.Errorf("Expected: %v, got: %v", ErrNotFound, err)
+	}
+}
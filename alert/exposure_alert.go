@@ -0,0 +1,147 @@
+package alert
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/stevenwilkin/treasury/state"
+	"github.com/stevenwilkin/treasury/symbol"
+)
+
+type ExposureAlert struct {
+	active    bool
+	state     *state.State
+	threshold float64
+	usd       bool
+}
+
+func (a *ExposureAlert) unit() string {
+	if a.usd {
+		return "USD"
+	}
+
+	return "BTC"
+}
+
+func (a *ExposureAlert) Description() string {
+	return fmt.Sprintf("Exposure alert at %.2f %s", a.threshold, a.unit())
+}
+
+func (a *ExposureAlert) Message() string {
+	s := a.state.Snapshot()
+
+	btcusdt := s.Symbol(symbol.BTCUSDT)
+	if btcusdt <= 0 {
+		return "Exposure unknown without a BTCUSDT price"
+	}
+
+	exposure := s.Exposure()
+
+	return fmt.Sprintf("Exposure: %f BTC (%.2f USD)", exposure, exposure*btcusdt)
+}
+
+func (a *ExposureAlert) Active() bool {
+	return a.active
+}
+
+func (a *ExposureAlert) Priority() bool {
+	return true
+}
+
+func (a *ExposureAlert) Activate() {
+	a.active = true
+}
+
+func (a *ExposureAlert) Deactivate() {
+	a.active = false
+}
+
+func (a *ExposureAlert) Value() float64 {
+	s := a.state.Snapshot()
+
+	if s.Symbol(symbol.BTCUSDT) <= 0 {
+		return 0
+	}
+
+	exposure := s.Exposure()
+	if a.usd {
+		exposure *= s.Symbol(symbol.BTCUSDT)
+	}
+
+	return exposure
+}
+
+type exposureParams struct {
+	Threshold float64
+	Unit      string
+}
+
+func (a *ExposureAlert) Type() string {
+	return "exposure"
+}
+
+func (a *ExposureAlert) Params() interface{} {
+	return exposureParams{
+		Threshold: a.threshold,
+		Unit:      strings.ToLower(a.unit())}
+}
+
+func (a *ExposureAlert) Check() bool {
+	s := a.state.Snapshot()
+
+	btcusdt := s.Symbol(symbol.BTCUSDT)
+	if btcusdt <= 0 || len(s.ExposureWarnings()) > 0 {
+		return false
+	}
+
+	exposure := math.Abs(s.Exposure())
+	if a.usd {
+		exposure *= btcusdt
+	}
+
+	return exposure >= a.threshold
+}
+
+func (a *ExposureAlert) Cleared(margin float64) bool {
+	s := a.state.Snapshot()
+
+	btcusdt := s.Symbol(symbol.BTCUSDT)
+	if btcusdt <= 0 || len(s.ExposureWarnings()) > 0 {
+		return false
+	}
+
+	exposure := math.Abs(s.Exposure())
+	if a.usd {
+		exposure *= btcusdt
+	}
+
+	return exposure < a.threshold-margin
+}
+
+func NewExposureAlert(s *state.State, threshold float64, usd bool) *ExposureAlert {
+	return &ExposureAlert{
+		active:    true,
+		state:     s,
+		threshold: threshold,
+		usd:       usd}
+}
+
+func (a *Alerter) AddExposureAlert(threshold float64, usd bool) int {
+	alert := NewExposureAlert(a.state, threshold, usd)
+	return a.AddAlert(alert)
+}
+
+func init() {
+	Register("exposure", func(s *state.State, b json.RawMessage) (Alert, error) {
+		var p exposureParams
+		if err := json.Unmarshal(b, &p); err != nil {
+			return nil, err
+		}
+
+		return NewExposureAlert(s, p.Threshold, p.Unit == "usd"), nil
+	})
+}
+
+var _ PersistentAlert = &ExposureAlert{}
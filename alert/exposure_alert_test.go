@@ -0,0 +1,150 @@
+package alert
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stevenwilkin/treasury/asset"
+	"github.com/stevenwilkin/treasury/state"
+	"github.com/stevenwilkin/treasury/symbol"
+	"github.com/stevenwilkin/treasury/venue"
+)
+
+func TestExposureAlertDescription(t *testing.T) {
+	alert := NewExposureAlert(nil, 0.5, false)
+
+	expected := "Exposure alert at 0.50 BTC"
+	if alert.Description() != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Description())
+	}
+
+	alert = NewExposureAlert(nil, 10000, true)
+
+	expected = "Exposure alert at 10000.00 USD"
+	if alert.Description() != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Description())
+	}
+}
+
+func TestExposureAlertMessage(t *testing.T) {
+	state := state.NewState()
+	state.SetSymbol(symbol.BTCUSDT, 20000)
+	state.SetAsset(venue.Deribit, asset.BTC, 1.5)
+	state.SetSize(20000)
+	alert := NewExposureAlert(state, 0.5, false)
+
+	expected := "Exposure: 0.500000 BTC (10000.00 USD)"
+	if alert.Message() != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Message())
+	}
+}
+
+func TestExposureAlertPriority(t *testing.T) {
+	if !NewExposureAlert(nil, 0.5, false).Priority() {
+		t.Error("Alert should be a priority")
+	}
+}
+
+func TestExposureAlertDeactivate(t *testing.T) {
+	alert := NewExposureAlert(nil, 0.5, false)
+
+	if !alert.Active() {
+		t.Error("Alert should be active")
+	}
+
+	alert.Deactivate()
+
+	if alert.Active() {
+		t.Error("Alert should be inactive")
+	}
+}
+
+func TestExposureAlertCheck(t *testing.T) {
+	state := state.NewState()
+	alert := NewExposureAlert(state, 0.5, false)
+	state.SetAsset(venue.Deribit, asset.BTC, 1)
+	state.SetSize(20000)
+
+	if alert.Check() {
+		t.Error("Alert should not be triggered without a price")
+	}
+
+	state.SetSymbol(symbol.BTCUSDT, 20000)
+
+	if alert.Check() {
+		t.Error("Alert should not be triggered")
+	}
+
+	state.SetSize(40000)
+
+	if !alert.Check() {
+		t.Error("Alert should be triggered by short exposure")
+	}
+
+	state.SetSize(0)
+
+	if !alert.Check() {
+		t.Error("Alert should be triggered by long exposure")
+	}
+}
+
+func TestExposureAlertCheckUSD(t *testing.T) {
+	state := state.NewState()
+	alert := NewExposureAlert(state, 10000, true)
+	state.SetSymbol(symbol.BTCUSDT, 20000)
+	state.SetAsset(venue.Deribit, asset.BTC, 1)
+	state.SetSize(10000)
+
+	if !alert.Check() {
+		t.Error("Alert should be triggered")
+	}
+
+	state.SetSize(10001)
+
+	if alert.Check() {
+		t.Error("Alert should not be triggered")
+	}
+}
+
+func TestExposureAlertCheckStaleBalance(t *testing.T) {
+	state := state.NewState()
+	state.SetStaleAfter(time.Millisecond)
+	alert := NewExposureAlert(state, 0.5, false)
+	state.SetSymbol(symbol.BTCUSDT, 20000)
+	state.SetAssetFrom(venue.Deribit, asset.BTC, 1, "deribit")
+
+	if !alert.Check() {
+		t.Error("Alert should be triggered")
+	}
+
+	time.Sleep(2 * time.Millisecond)
+
+	if alert.Check() {
+		t.Error("Alert should not be triggered by a stale balance")
+	}
+}
+
+func TestExposureAlertMissingPrice(t *testing.T) {
+	s := state.NewState()
+	s.SetPath(filepath.Join(t.TempDir(), "state.json"))
+	s.SetAsset(venue.Deribit, asset.BTC, 1)
+
+	alerter := NewAlerter(s, &TestNotifier{})
+	alerter.AddExposureAlert(1, false)
+
+	for _, size := range []int{0, 20000} {
+		s.SetSize(size)
+
+		alerter.CheckAlerts()
+		alerter.Persist()
+
+		if value := s.GetAlerts()[0].Value; value != 0 {
+			t.Errorf("Expected: '%f', got: '%f'", 0.0, value)
+		}
+
+		if err := s.Save(); err != nil {
+			t.Error(err)
+		}
+	}
+}
@@ -0,0 +1,105 @@
+package alert
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stevenwilkin/treasury/feed"
+)
+
+type FeedStatuser interface {
+	Status() map[feed.Feed]feed.FeedStatus
+}
+
+type FeedAlert struct {
+	active  bool
+	feeds   FeedStatuser
+	feed    feed.Feed
+	window  time.Duration
+	created time.Time
+}
+
+func (a *FeedAlert) Description() string {
+	if a.window > 0 {
+		return fmt.Sprintf("Feed alert for %s after %s", a.feed, a.window)
+	}
+
+	return fmt.Sprintf("Feed alert for %s", a.feed)
+}
+
+func (a *FeedAlert) Message() string {
+	status := a.feeds.Status()[a.feed]
+
+	if status.Failed() {
+		return fmt.Sprintf("Feed %s failed", a.feed)
+	}
+
+	if status.LastUpdate.IsZero() {
+		return fmt.Sprintf("Feed %s not updated", a.feed)
+	}
+
+	return fmt.Sprintf("Feed %s not updated for %s",
+		a.feed, time.Since(status.LastUpdate).Round(time.Second))
+}
+
+func (a *FeedAlert) Active() bool {
+	return a.active
+}
+
+func (a *FeedAlert) Priority() bool {
+	return true
+}
+
+func (a *FeedAlert) Activate() {
+	a.active = true
+}
+
+func (a *FeedAlert) Deactivate() {
+	a.active = false
+}
+
+func (a *FeedAlert) Value() float64 {
+	status := a.feeds.Status()[a.feed]
+	if status.LastUpdate.IsZero() {
+		return 0
+	}
+
+	return time.Since(status.LastUpdate).Seconds()
+}
+
+func (a *FeedAlert) Check() bool {
+	status, ok := a.feeds.Status()[a.feed]
+	if !ok {
+		return false
+	}
+
+	if status.Failed() {
+		return true
+	}
+
+	if a.window <= 0 {
+		return false
+	}
+
+	lastUpdate := status.LastUpdate
+	if lastUpdate.IsZero() {
+		lastUpdate = a.created
+	}
+
+	return time.Since(lastUpdate) > a.window
+}
+
+func (a *FeedAlert) Cleared(_ float64) bool {
+	return !a.Check()
+}
+
+func NewFeedAlert(feeds FeedStatuser, f feed.Feed, window time.Duration) *FeedAlert {
+	return &FeedAlert{
+		active:  true,
+		feeds:   feeds,
+		feed:    f,
+		window:  window,
+		created: time.Now()}
+}
+
+var _ Alert = &FeedAlert{}
@@ -0,0 +1,119 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stevenwilkin/treasury/feed"
+)
+
+type TestFeeds map[feed.Feed]feed.FeedStatus
+
+func (tf TestFeeds) Status() map[feed.Feed]feed.FeedStatus { return tf }
+
+func TestFeedAlertDescription(t *testing.T) {
+	alert := NewFeedAlert(TestFeeds{}, feed.USDTHB, 0)
+
+	expected := "Feed alert for USDTHB"
+	if alert.Description() != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Description())
+	}
+
+	alert = NewFeedAlert(TestFeeds{}, feed.USDTHB, time.Minute)
+
+	expected = "Feed alert for USDTHB after 1m0s"
+	if alert.Description() != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Description())
+	}
+}
+
+func TestFeedAlertMessage(t *testing.T) {
+	feeds := TestFeeds{feed.USDTHB: {Active: false, Errors: 100}}
+	alert := NewFeedAlert(feeds, feed.USDTHB, time.Minute)
+
+	expected := "Feed USDTHB failed"
+	if alert.Message() != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Message())
+	}
+
+	feeds[feed.USDTHB] = feed.FeedStatus{
+		Active: true, LastUpdate: time.Now().Add(-2 * time.Minute)}
+
+	expected = "Feed USDTHB not updated for 2m0s"
+	if alert.Message() != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Message())
+	}
+}
+
+func TestFeedAlertDeactivate(t *testing.T) {
+	alert := NewFeedAlert(TestFeeds{}, feed.USDTHB, 0)
+
+	if !alert.Active() {
+		t.Error("Alert should be active")
+	}
+
+	alert.Deactivate()
+
+	if alert.Active() {
+		t.Error("Alert should be inactive")
+	}
+}
+
+func TestFeedAlertCheck(t *testing.T) {
+	feeds := TestFeeds{}
+	alert := NewFeedAlert(feeds, feed.USDTHB, 0)
+
+	if alert.Check() {
+		t.Error("Alert should not be triggered for a feed that was not added")
+	}
+
+	feeds[feed.USDTHB] = feed.FeedStatus{Active: true}
+
+	if alert.Check() {
+		t.Error("Alert should not be triggered for an active feed")
+	}
+
+	feeds[feed.USDTHB] = feed.FeedStatus{Active: false, Errors: 100}
+
+	if !alert.Check() {
+		t.Error("Alert should be triggered for a failed feed")
+	}
+}
+
+func TestFeedAlertIgnoresReconnect(t *testing.T) {
+	feeds := TestFeeds{feed.USDTHB: {Active: false, Errors: 1}}
+	alert := NewFeedAlert(feeds, feed.USDTHB, time.Minute)
+
+	if alert.Check() {
+		t.Error("Alert should not be triggered by a feed backing off")
+	}
+
+	feeds[feed.USDTHB] = feed.FeedStatus{Active: true, LastUpdate: time.Now()}
+
+	if alert.Check() {
+		t.Error("Alert should not be triggered once the feed reconnects")
+	}
+}
+
+func TestFeedAlertCheckWindow(t *testing.T) {
+	feeds := TestFeeds{feed.USDTHB: {Active: true}}
+	alert := NewFeedAlert(feeds, feed.USDTHB, time.Minute)
+
+	if alert.Check() {
+		t.Error("Alert should not be triggered before the window has passed")
+	}
+
+	feeds[feed.USDTHB] = feed.FeedStatus{
+		Active: true, LastUpdate: time.Now().Add(-30 * time.Second)}
+
+	if alert.Check() {
+		t.Error("Alert should not be triggered by a recent update")
+	}
+
+	feeds[feed.USDTHB] = feed.FeedStatus{
+		Active: true, LastUpdate: time.Now().Add(-2 * time.Minute)}
+
+	if !alert.Check() {
+		t.Error("Alert should be triggered by a stale feed")
+	}
+}
@@ -1,6 +1,7 @@
 package alert
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/stevenwilkin/treasury/state"
@@ -29,25 +30,51 @@ func (a *FundingAlert) Priority() bool {
 	return true
 }
 
+func (a *FundingAlert) Activate() {
+	a.active = true
+}
+
 func (a *FundingAlert) Deactivate() {
 	a.active = false
 }
 
+func (a *FundingAlert) Value() float64 {
+	return a.state.GetFundingRate()
+}
+
+func (a *FundingAlert) Type() string {
+	return "funding"
+}
+
+func (a *FundingAlert) Params() interface{} {
+	return struct{}{}
+}
+
 func (a *FundingAlert) Check() bool {
 	funding := a.state.GetFundingRate()
 
 	return funding < 0
 }
 
+func (a *FundingAlert) Cleared(margin float64) bool {
+	return a.state.GetFundingRate() >= margin
+}
+
 func NewFundingAlert(s *state.State) *FundingAlert {
 	return &FundingAlert{
 		active: true,
 		state:  s}
 }
 
-func (a *Alerter) AddFundingAlert() {
+func (a *Alerter) AddFundingAlert() int {
 	alert := NewFundingAlert(a.state)
-	a.AddAlert(alert)
+	return a.AddAlert(alert)
+}
+
+func init() {
+	Register("funding", func(s *state.State, _ json.RawMessage) (Alert, error) {
+		return NewFundingAlert(s), nil
+	})
 }
 
-var _ Alert = &FundingAlert{}
+var _ PersistentAlert = &FundingAlert{}
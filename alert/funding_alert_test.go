@@ -54,3 +54,18 @@ func TestCheckOnNegativeFundingRate(t *testing.T) {
 		t.Error("Alert should be triggered")
 	}
 }
+
+func TestFundingAlertCleared(t *testing.T) {
+	s := state.NewState()
+	alert := NewFundingAlert(s)
+
+	s.SetFundingRate(0.00005)
+	if alert.Cleared(0.0001) {
+		t.Error("Should not clear within margin")
+	}
+
+	s.SetFundingRate(0.0002)
+	if !alert.Cleared(0.0001) {
+		t.Error("Should clear beyond margin")
+	}
+}
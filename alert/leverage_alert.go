@@ -1,6 +1,7 @@
 package alert
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/stevenwilkin/treasury/state"
@@ -17,8 +18,10 @@ func (a *LeverageAlert) Description() string {
 }
 
 func (a *LeverageAlert) Message() string {
+	s := a.state.Snapshot()
+
 	return fmt.Sprintf("Leverage: %.2f %.2f",
-		a.state.GetLeverageDeribit(), a.state.GetLeverageBybit())
+		s.GetLeverageDeribit(), s.GetLeverageBybit())
 }
 
 func (a *LeverageAlert) Active() bool {
@@ -29,13 +32,49 @@ func (a *LeverageAlert) Priority() bool {
 	return false
 }
 
+func (a *LeverageAlert) Cleared(margin float64) bool {
+	return a.Value() < a.threshold-margin
+}
+
+func (a *LeverageAlert) Activate() {
+	a.active = true
+}
+
 func (a *LeverageAlert) Deactivate() {
 	a.active = false
 }
 
+func (a *LeverageAlert) Value() float64 {
+	max := 0.0
+	for _, leverage := range a.state.GetLeverages() {
+		if leverage > max {
+			max = leverage
+		}
+	}
+
+	return max
+}
+
+type leverageParams struct {
+	Threshold float64
+}
+
+func (a *LeverageAlert) Type() string {
+	return "leverage"
+}
+
+func (a *LeverageAlert) Params() interface{} {
+	return leverageParams{Threshold: a.threshold}
+}
+
 func (a *LeverageAlert) Check() bool {
-	return a.state.GetLeverageDeribit() >= a.threshold ||
-		a.state.GetLeverageBybit() >= a.threshold
+	for _, leverage := range a.state.GetLeverages() {
+		if leverage >= a.threshold {
+			return true
+		}
+	}
+
+	return false
 }
 
 func NewLeverageAlert(s *state.State, threshold float64) *LeverageAlert {
@@ -45,9 +84,20 @@ func NewLeverageAlert(s *state.State, threshold float64) *LeverageAlert {
 		threshold: threshold}
 }
 
-func (a *Alerter) AddLeverageAlert(threshold float64) {
+func (a *Alerter) AddLeverageAlert(threshold float64) int {
 	alert := NewLeverageAlert(a.state, threshold)
-	a.AddAlert(alert)
+	return a.AddAlert(alert)
+}
+
+func init() {
+	Register("leverage", func(s *state.State, b json.RawMessage) (Alert, error) {
+		var p leverageParams
+		if err := json.Unmarshal(b, &p); err != nil {
+			return nil, err
+		}
+
+		return NewLeverageAlert(s, p.Threshold), nil
+	})
 }
 
-var _ Alert = &LeverageAlert{}
+var _ PersistentAlert = &LeverageAlert{}
@@ -0,0 +1,229 @@
+package alert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stevenwilkin/treasury/state"
+	"github.com/stevenwilkin/treasury/symbol"
+)
+
+type Move int
+
+const (
+	Either Move = iota
+	Up
+	Down
+)
+
+func moves() []string {
+	return []string{"either", "up", "down"}
+}
+
+func (m Move) String() string {
+	return moves()[m]
+}
+
+func ParseMove(s string) (Move, error) {
+	for i, m := range moves() {
+		if strings.ToLower(s) == m {
+			return Move(i), nil
+		}
+	}
+	return Either, errors.New("Invalid move")
+}
+
+type sample struct {
+	time  time.Time
+	price float64
+}
+
+type MoveAlert struct {
+	active     bool
+	state      *state.State
+	symbol     symbol.Symbol
+	move       Move
+	percentage float64
+	window     time.Duration
+	samples    []sample
+	change     float64
+}
+
+func (a *MoveAlert) Description() string {
+	return fmt.Sprintf("Move alert on %s %.2f%% %s within %s",
+		a.symbol, a.percentage, a.move, a.window)
+}
+
+func (a *MoveAlert) Message() string {
+	return fmt.Sprintf("%s has moved %+.2f%% within %s to %.2f",
+		a.symbol, a.change, a.window, a.state.Symbol(a.symbol))
+}
+
+func (a *MoveAlert) Active() bool {
+	return a.active
+}
+
+func (a *MoveAlert) Priority() bool {
+	return false
+}
+
+func (a *MoveAlert) Activate() {
+	a.samples = nil
+	a.active = true
+}
+
+func (a *MoveAlert) Deactivate() {
+	a.active = false
+}
+
+func (a *MoveAlert) Value() float64 {
+	return a.state.Symbol(a.symbol)
+}
+
+type moveParams struct {
+	Symbol     string
+	Move       string
+	Percentage float64
+	Window     string
+}
+
+func (a *MoveAlert) Type() string {
+	return "move"
+}
+
+func (a *MoveAlert) Params() interface{} {
+	return moveParams{
+		Symbol:     a.symbol.String(),
+		Move:       a.move.String(),
+		Percentage: a.percentage,
+		Window:     a.window.String()}
+}
+
+func (a *MoveAlert) record(now time.Time, price float64) {
+	cutoff := now.Add(-a.window)
+
+	i := 0
+	for i < len(a.samples) && a.samples[i].time.Before(cutoff) {
+		i++
+	}
+
+	a.samples = append(a.samples[i:], sample{time: now, price: price})
+}
+
+func (a *MoveAlert) changes(now time.Time, price float64) (float64, float64) {
+	a.record(now, price)
+
+	low, high := price, price
+	for _, s := range a.samples {
+		if s.price < low {
+			low = s.price
+		}
+		if s.price > high {
+			high = s.price
+		}
+	}
+
+	rise := (price - low) / low * 100
+	fall := (price - high) / high * 100
+
+	return rise, fall
+}
+
+func (a *MoveAlert) check(now time.Time, price float64) bool {
+	rise, fall := a.changes(now, price)
+
+	if a.move != Down && rise >= a.percentage {
+		a.change = rise
+		return true
+	}
+
+	if a.move != Up && -fall >= a.percentage {
+		a.change = fall
+		return true
+	}
+
+	return false
+}
+
+func (a *MoveAlert) Check() bool {
+	s := a.state.Snapshot()
+
+	if len(s.SymbolWarnings(a.symbol)) > 0 {
+		return false
+	}
+
+	price := s.Symbol(a.symbol)
+	if price <= 0 {
+		return false
+	}
+
+	return a.check(time.Now(), price)
+}
+
+func (a *MoveAlert) cleared(now time.Time, price, margin float64) bool {
+	rise, fall := a.changes(now, price)
+
+	return (a.move == Down || rise < a.percentage-margin) &&
+		(a.move == Up || -fall < a.percentage-margin)
+}
+
+func (a *MoveAlert) Cleared(margin float64) bool {
+	s := a.state.Snapshot()
+
+	if len(s.SymbolWarnings(a.symbol)) > 0 {
+		return false
+	}
+
+	price := s.Symbol(a.symbol)
+	if price <= 0 {
+		return false
+	}
+
+	return a.cleared(time.Now(), price, margin)
+}
+
+func NewMoveAlert(s *state.State, sym symbol.Symbol, m Move, percentage float64, window time.Duration) *MoveAlert {
+	return &MoveAlert{
+		active:     true,
+		state:      s,
+		symbol:     sym,
+		move:       m,
+		percentage: percentage,
+		window:     window}
+}
+
+func (a *Alerter) AddMoveAlert(sym symbol.Symbol, m Move, percentage float64, window time.Duration) int {
+	alert := NewMoveAlert(a.state, sym, m, percentage, window)
+	return a.AddAlert(alert)
+}
+
+func init() {
+	Register("move", func(s *state.State, b json.RawMessage) (Alert, error) {
+		var p moveParams
+		if err := json.Unmarshal(b, &p); err != nil {
+			return nil, err
+		}
+
+		sym, err := symbol.FromString(p.Symbol)
+		if err != nil {
+			return nil, err
+		}
+
+		m, err := ParseMove(p.Move)
+		if err != nil {
+			return nil, err
+		}
+
+		window, err := time.ParseDuration(p.Window)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewMoveAlert(s, sym, m, p.Percentage, window), nil
+	})
+}
+
+var _ PersistentAlert = &MoveAlert{}
@@ -0,0 +1,136 @@
+package alert
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stevenwilkin/treasury/state"
+	"github.com/stevenwilkin/treasury/symbol"
+)
+
+func TestMoveAlertDescription(t *testing.T) {
+	alert := NewMoveAlert(nil, symbol.BTCUSDT, Up, 5, time.Hour)
+
+	expected := "Move alert on BTCUSDT 5.00% up within 1h0m0s"
+	if alert.Description() != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Description())
+	}
+}
+
+func TestMoveAlertMessage(t *testing.T) {
+	state := state.NewState()
+	state.SetSymbol(symbol.BTCUSDT, 21000)
+	alert := NewMoveAlert(state, symbol.BTCUSDT, Either, 5, time.Hour)
+	alert.change = 5
+
+	expected := "BTCUSDT has moved +5.00% within 1h0m0s to 21000.00"
+	if alert.Message() != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Message())
+	}
+}
+
+func TestMoveAlertDeactivate(t *testing.T) {
+	alert := NewMoveAlert(nil, symbol.BTCUSDT, Either, 5, time.Hour)
+
+	if !alert.Active() {
+		t.Error("Alert should be active")
+	}
+
+	alert.Deactivate()
+
+	if alert.Active() {
+		t.Error("Alert should be inactive")
+	}
+}
+
+func TestMoveAlertCheckUp(t *testing.T) {
+	alert := NewMoveAlert(nil, symbol.BTCUSDT, Up, 5, time.Hour)
+	now := time.Now()
+
+	if alert.check(now, 20000) {
+		t.Error("Alert should not be triggered")
+	}
+
+	if alert.check(now.Add(time.Minute), 19000) {
+		t.Error("Alert should not be triggered by a fall")
+	}
+
+	if !alert.check(now.Add(2*time.Minute), 20000) {
+		t.Error("Alert should be triggered")
+	}
+}
+
+func TestMoveAlertCheckDown(t *testing.T) {
+	alert := NewMoveAlert(nil, symbol.BTCUSDT, Down, 5, time.Hour)
+	now := time.Now()
+
+	alert.check(now, 20000)
+
+	if alert.check(now.Add(time.Minute), 21000) {
+		t.Error("Alert should not be triggered by a rise")
+	}
+
+	if !alert.check(now.Add(2*time.Minute), 19900) {
+		t.Error("Alert should be triggered")
+	}
+
+	if alert.change >= 0 {
+		t.Errorf("Change should be negative, got %f", alert.change)
+	}
+}
+
+func TestMoveAlertCheckOutsideWindow(t *testing.T) {
+	alert := NewMoveAlert(nil, symbol.BTCUSDT, Either, 5, time.Hour)
+	now := time.Now()
+
+	alert.check(now, 20000)
+
+	if alert.check(now.Add(2*time.Hour), 22000) {
+		t.Error("Alert should not be triggered by a move outside the window")
+	}
+
+	if len(alert.samples) != 1 {
+		t.Errorf("Should discard old samples, have %d", len(alert.samples))
+	}
+}
+
+func TestMoveAlertCheckStalePrice(t *testing.T) {
+	state := state.NewState()
+	alert := NewMoveAlert(state, symbol.BTCUSDT, Either, 5, time.Hour)
+
+	if alert.Check() {
+		t.Error("Alert should not be triggered without a price")
+	}
+
+	if len(alert.samples) != 0 {
+		t.Error("Should not record missing prices")
+	}
+}
+
+func TestParseMove(t *testing.T) {
+	if m, err := ParseMove("Up"); err != nil || m != Up {
+		t.Error("Should parse up")
+	}
+
+	if _, err := ParseMove("sideways"); err == nil {
+		t.Error("Should return an error")
+	}
+}
+
+func TestMoveAlertCleared(t *testing.T) {
+	now := time.Now()
+	alert := NewMoveAlert(state.NewState(), symbol.BTCUSDT, Up, 5, time.Hour)
+
+	alert.check(now, 100)
+	if !alert.check(now.Add(time.Minute), 106) {
+		t.Fatal("Should trigger on rise")
+	}
+
+	if alert.cleared(now.Add(2*time.Minute), 104, 1) {
+		t.Error("Should not clear within margin")
+	}
+
+	if !alert.cleared(now.Add(90*time.Minute), 104, 1) {
+		t.Error("Should clear once the rise leaves the window")
+	}
+}
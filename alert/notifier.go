@@ -31,4 +31,19 @@ func NewPriorityNotifier(priority, normal Notifier) *PriorityNotifier {
 		normal:   normal}
 }
 
+type MultiNotifier []Notifier
+
+func (mn MultiNotifier) Notify(a Alert) error {
+	var e error
+
+	for _, n := range mn {
+		if err := n.Notify(a); err != nil {
+			e = err
+		}
+	}
+
+	return e
+}
+
 var _ Notifier = &PriorityNotifier{}
+var _ Notifier = MultiNotifier{}
@@ -64,3 +64,19 @@ func TestPriorityNotifierWithFailingPriorityNotification(t *testing.T) {
 		t.Error("Should send normal notification")
 	}
 }
+
+func TestMultiNotifier(t *testing.T) {
+	a := &TestAlert{}
+	first := &TestNotifier{}
+	last := &TestNotifier{}
+
+	err := MultiNotifier{first, &FailingTestNotifier{}, last}.Notify(a)
+
+	if err == nil {
+		t.Error("Should return an error")
+	}
+
+	if first.alert != a || last.alert != a {
+		t.Error("Should notify every notifier")
+	}
+}
@@ -0,0 +1,378 @@
+package alert
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultMaxAttempts = 10
+	retryBackoff       = 10 * time.Second
+	maxRetryBackoff    = 10 * time.Minute
+	outboxInterval     = time.Second
+)
+
+var (
+	ErrDeliveryNotFound = errors.New("Notification not found")
+	ErrNotifierNotFound = errors.New("Notifier not configured")
+)
+
+type Delivery struct {
+	Id          int
+	Notifier    string
+	AlertId     int
+	Description string
+	Message     string
+	Priority    bool
+	Created     time.Time
+	Attempts    int
+	NextAttempt time.Time
+	LastError   string
+	Failed      bool
+}
+
+func (d *Delivery) notification() *notification {
+	return &notification{
+		id:          d.AlertId,
+		description: d.Description,
+		message:     d.Message,
+		priority:    d.Priority}
+}
+
+type Outbox struct {
+	mu          sync.Mutex
+	path        string
+	notifiers   map[string]Notifier
+	wake        map[string]chan struct{}
+	deliveries  []*Delivery
+	nextId      int
+	maxAttempts int
+}
+
+type outboxNotifier struct {
+	outbox *Outbox
+	name   string
+}
+
+func (n *outboxNotifier) Notify(a Alert) error {
+	return n.outbox.enqueue(n.name, a)
+}
+
+func (o *Outbox) SetMaxAttempts(attempts int) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.maxAttempts = attempts
+}
+
+func (o *Outbox) Notifier(name string, n Notifier) Notifier {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.notifiers[name] = n
+	if _, ok := o.wake[name]; !ok {
+		o.wake[name] = make(chan struct{}, 1)
+	}
+
+	return &outboxNotifier{outbox: o, name: name}
+}
+
+func (o *Outbox) enqueue(name string, a Alert) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.nextId++
+	now := time.Now()
+
+	o.deliveries = append(o.deliveries, &Delivery{
+		Id:          o.nextId,
+		Notifier:    name,
+		AlertId:     AlertId(a),
+		Description: a.Description(),
+		Message:     a.Message(),
+		Priority:    a.Priority(),
+		Created:     now,
+		NextAttempt: now})
+
+	select {
+	case o.wake[name] <- struct{}{}:
+	default:
+	}
+
+	return o.save()
+}
+
+func (o *Outbox) Deliveries() []Delivery {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	results := make([]Delivery, len(o.deliveries))
+	for i, d := range o.deliveries {
+		results[i] = *d
+	}
+
+	return results
+}
+
+func (o *Outbox) find(id int) (int, *Delivery) {
+	for i, d := range o.deliveries {
+		if d.Id == id {
+			return i, d
+		}
+	}
+
+	return -1, nil
+}
+
+func (o *Outbox) Retry(id int) error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	_, d := o.find(id)
+	if d == nil {
+		return ErrDeliveryNotFound
+	}
+
+	if _, ok := o.notifiers[d.Notifier]; !ok {
+		return ErrNotifierNotFound
+	}
+
+	d.Failed = false
+	d.Attempts = 0
+	d.NextAttempt = time.Now()
+
+	select {
+	case o.wake[d.Notifier] <- struct{}{}:
+	default:
+	}
+
+	return o.save()
+}
+
+func backoff(attempts int) time.Duration {
+	d := retryBackoff
+	for i := 1; i < attempts && d < maxRetryBackoff; i++ {
+		d *= 2
+	}
+
+	if d > maxRetryBackoff {
+		return maxRetryBackoff
+	}
+
+	return d
+}
+
+func (o *Outbox) due(name string, now time.Time) *Delivery {
+	for _, d := range o.deliveries {
+		if d.Notifier == name && !d.Failed && !d.NextAttempt.After(now) {
+			copy := *d
+			return &copy
+		}
+	}
+
+	return nil
+}
+
+func (o *Outbox) complete(id int, err error, now time.Time) {
+	i, d := o.find(id)
+	if d == nil {
+		return
+	}
+
+	if err == nil {
+		o.deliveries = append(o.deliveries[:i], o.deliveries[i+1:]...)
+		return
+	}
+
+	d.Attempts++
+	d.LastError = err.Error()
+	d.NextAttempt = now.Add(backoff(d.Attempts))
+
+	fields := log.Fields{
+		"id":       d.Id,
+		"notifier": d.Notifier,
+		"attempts": d.Attempts,
+	}
+
+	if d.Attempts >= o.maxAttempts {
+		d.Failed = true
+		log.WithFields(fields).Error("Notification failed: ", err.Error())
+		return
+	}
+
+	log.WithFields(fields).Warn("Notification will be retried: ", err.Error())
+}
+
+func (o *Outbox) deliver(name string, now time.Time) bool {
+	o.mu.Lock()
+	d := o.due(name, now)
+	n := o.notifiers[name]
+	o.mu.Unlock()
+
+	if d == nil || n == nil {
+		return false
+	}
+
+	err := n.Notify(d.notification())
+
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	o.complete(d.Id, err, now)
+	if err := o.save(); err != nil {
+		log.Error(err.Error())
+	}
+
+	return true
+}
+
+func (o *Outbox) work(ctx context.Context, name string) {
+	ticker := time.NewTicker(outboxInterval)
+	defer ticker.Stop()
+
+	o.mu.Lock()
+	wake := o.wake[name]
+	o.mu.Unlock()
+
+	for {
+		for o.deliver(name, time.Now()) {
+			if ctx.Err() != nil {
+				return
+			}
+		}
+
+		select {
+		case <-ticker.C:
+		case <-wake:
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+func (o *Outbox) deadLetterOrphans() {
+	orphaned := false
+
+	for _, d := range o.deliveries {
+		if _, ok := o.notifiers[d.Notifier]; ok || d.Failed {
+			continue
+		}
+
+		d.Failed = true
+		d.LastError = ErrNotifierNotFound.Error()
+		orphaned = true
+
+		log.WithFields(log.Fields{
+			"id":       d.Id,
+			"notifier": d.Notifier,
+		}).Error("Notification failed: ", d.LastError)
+	}
+
+	if orphaned {
+		if err := o.save(); err != nil {
+			log.Error(err.Error())
+		}
+	}
+}
+
+func (o *Outbox) Run(ctx context.Context) {
+	o.mu.Lock()
+	o.deadLetterOrphans()
+
+	names := []string{}
+	for name := range o.notifiers {
+		names = append(names, name)
+	}
+	o.mu.Unlock()
+
+	var wg sync.WaitGroup
+	for _, name := range names {
+		wg.Add(1)
+		go func(name string) {
+			defer wg.Done()
+			o.work(ctx, name)
+		}(name)
+	}
+
+	wg.Wait()
+}
+
+func (o *Outbox) save() error {
+	b, err := json.MarshalIndent(o.deliveries, "", "\t")
+	if err != nil {
+		return err
+	}
+
+	tmpFile, err := ioutil.TempFile(filepath.Dir(o.path), "outbox")
+	if err != nil {
+		return err
+	}
+
+	_, err = tmpFile.Write(b)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.RemoveAll(tmpFile.Name())
+		return err
+	}
+
+	err = os.Rename(tmpFile.Name(), o.path)
+	if err != nil {
+		os.RemoveAll(tmpFile.Name())
+		return err
+	}
+
+	return nil
+}
+
+func (o *Outbox) Load() error {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+
+	b, err := ioutil.ReadFile(o.path)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	var deliveries []*Delivery
+	if err := json.Unmarshal(b, &deliveries); err != nil {
+		return err
+	}
+
+	sort.Slice(deliveries, func(i, j int) bool {
+		return deliveries[i].Id < deliveries[j].Id
+	})
+
+	for _, d := range deliveries {
+		if d.Id > o.nextId {
+			o.nextId = d.Id
+		}
+	}
+
+	o.deliveries = deliveries
+
+	return nil
+}
+
+func NewOutbox(path string) *Outbox {
+	return &Outbox{
+		path:        path,
+		notifiers:   map[string]Notifier{},
+		wake:        map[string]chan struct{}{},
+		deliveries:  []*Delivery{},
+		maxAttempts: defaultMaxAttempts}
+}
+
+var _ Notifier = &outboxNotifier{}
@@ -0,0 +1,232 @@
+package alert
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+type CountingNotifier struct {
+	calls int
+	err   error
+}
+
+func (n *CountingNotifier) Notify(_ Alert) error {
+	n.calls++
+	return n.err
+}
+
+var _ Notifier = &CountingNotifier{}
+
+func outboxPath(t *testing.T) string {
+	return filepath.Join(t.TempDir(), "outbox.json")
+}
+
+func TestOutboxDelivers(t *testing.T) {
+	o := NewOutbox(outboxPath(t))
+	notifier := &CountingNotifier{}
+	o.Notifier("telegram", notifier).Notify(&notification{id: 3, message: "Alert"})
+
+	if !o.deliver("telegram", time.Now()) {
+		t.Fatal("Should attempt pending delivery")
+	}
+
+	if notifier.calls != 1 {
+		t.Errorf("Expected: 1, got: %d", notifier.calls)
+	}
+
+	if len(o.Deliveries()) != 0 {
+		t.Error("Should remove delivered notification")
+	}
+}
+
+func TestOutboxRetriesWithBackoff(t *testing.T) {
+	o := NewOutbox(outboxPath(t))
+	notifier := &CountingNotifier{err: errors.New("Fail")}
+	o.Notifier("telegram", notifier).Notify(&notification{})
+
+	now := time.Now()
+	o.deliver("telegram", now)
+
+	d := o.Deliveries()[0]
+	if d.Attempts != 1 || d.LastError != "Fail" || d.Failed {
+		t.Fatalf("Unexpected delivery %+v", d)
+	}
+
+	if o.deliver("telegram", now) {
+		t.Error("Should not retry before backoff")
+	}
+
+	if !o.deliver("telegram", d.NextAttempt) {
+		t.Error("Should retry after backoff")
+	}
+
+	if next := o.Deliveries()[0].NextAttempt; next.Sub(d.NextAttempt) < 2*retryBackoff {
+		t.Errorf("Should double backoff, got %s", next.Sub(d.NextAttempt))
+	}
+}
+
+func TestBackoff(t *testing.T) {
+	if backoff(1) != retryBackoff {
+		t.Errorf("Expected: %s, got: %s", retryBackoff, backoff(1))
+	}
+
+	if backoff(3) != 4*retryBackoff {
+		t.Errorf("Expected: %s, got: %s", 4*retryBackoff, backoff(3))
+	}
+
+	if backoff(20) != maxRetryBackoff {
+		t.Errorf("Expected: %s, got: %s", maxRetryBackoff, backoff(20))
+	}
+}
+
+func TestOutboxDeadLetter(t *testing.T) {
+	o := NewOutbox(outboxPath(t))
+	o.SetMaxAttempts(2)
+	notifier := &CountingNotifier{err: errors.New("Fail")}
+	o.Notifier("twilio", notifier).Notify(&notification{})
+
+	later := time.Now().Add(time.Hour)
+	o.deliver("twilio", later)
+	o.deliver("twilio", later.Add(time.Hour))
+
+	if !o.Deliveries()[0].Failed {
+		t.Fatal("Should fail after max attempts")
+	}
+
+	if o.deliver("twilio", later.Add(2*time.Hour)) {
+		t.Error("Should not retry failed delivery")
+	}
+
+	if err := o.Retry(o.Deliveries()[0].Id); err != nil {
+		t.Fatal(err)
+	}
+
+	notifier.err = nil
+	if !o.deliver("twilio", time.Now()) || len(o.Deliveries()) != 0 {
+		t.Error("Should deliver retried notification")
+	}
+
+	if err := o.Retry(42); err != ErrDeliveryNotFound {
+		t.Errorf("Expected: %v, got: %v", ErrDeliveryNotFound, err)
+	}
+}
+
+func TestOutboxNotifiersIndependent(t *testing.T) {
+	o := NewOutbox(outboxPath(t))
+	failing := &CountingNotifier{err: errors.New("Fail")}
+	working := &CountingNotifier{}
+	NewPriorityNotifier(
+		o.Notifier("twilio", failing),
+		o.Notifier("telegram", working)).Notify(&notification{priority: true})
+
+	now := time.Now()
+	o.deliver("twilio", now)
+	o.deliver("telegram", now)
+
+	deliveries := o.Deliveries()
+	if len(deliveries) != 1 || deliveries[0].Notifier != "twilio" {
+		t.Errorf("Should only retain failed delivery, got %+v", deliveries)
+	}
+
+	if working.calls != 1 {
+		t.Errorf("Expected: 1, got: %d", working.calls)
+	}
+}
+
+func TestOutboxPersistence(t *testing.T) {
+	path := outboxPath(t)
+	o := NewOutbox(path)
+	o.Notifier("telegram", &CountingNotifier{}).Notify(
+		&notification{id: 7, description: "Funding", message: "Funding", priority: true})
+
+	restored := NewOutbox(path)
+	if err := restored.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	deliveries := restored.Deliveries()
+	if len(deliveries) != 1 {
+		t.Fatalf("Expected: 1, got: %d", len(deliveries))
+	}
+
+	d := deliveries[0]
+	if d.AlertId != 7 || d.Message != "Funding" || !d.Priority {
+		t.Errorf("Unexpected delivery %+v", d)
+	}
+
+	notifier := &TestNotifier{}
+	restored.Notifier("telegram", notifier).Notify(&notification{})
+	if ids := restored.Deliveries(); ids[1].Id != d.Id+1 {
+		t.Errorf("Should continue ids, got %d", ids[1].Id)
+	}
+
+	restored.deliver("telegram", time.Now())
+	if notifier.id != 7 || notifier.alert.Message() != "Funding" {
+		t.Error("Should send restored notification")
+	}
+}
+
+func TestOutboxLoadMissing(t *testing.T) {
+	if err := NewOutbox(outboxPath(t)).Load(); err != nil {
+		t.Errorf("Should ignore missing outbox, got %v", err)
+	}
+}
+
+func TestOutboxRun(t *testing.T) {
+	o := NewOutbox(outboxPath(t))
+	notifier := &BlockingNotifier{
+		release: make(chan struct{}),
+		sent:    make(chan string, 1)}
+	n := o.Notifier("telegram", notifier)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		o.Run(ctx)
+		close(done)
+	}()
+
+	n.Notify(&notification{message: "Alert"})
+	close(notifier.release)
+
+	select {
+	case message := <-notifier.sent:
+		if message != "Alert" {
+			t.Errorf("Expected: 'Alert', got: '%s'", message)
+		}
+	case <-time.After(time.Second):
+		t.Error("Should send notification")
+	}
+
+	cancel()
+	<-done
+}
+
+func TestOutboxDeadLettersUnknownNotifier(t *testing.T) {
+	path := outboxPath(t)
+
+	o := NewOutbox(path)
+	o.Notifier("twilio +440000", &CountingNotifier{}).Notify(&notification{message: "Alert"})
+
+	o = NewOutbox(path)
+	if err := o.Load(); err != nil {
+		t.Fatal(err)
+	}
+	o.Notifier("twilio +440001", &CountingNotifier{})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	o.Run(ctx)
+
+	d := o.Deliveries()[0]
+	if !d.Failed || d.LastError != ErrNotifierNotFound.Error() {
+		t.Errorf("Should dead-letter delivery for unknown notifier, got %+v", d)
+	}
+
+	if err := o.Retry(d.Id); err != ErrNotifierNotFound {
+		t.Errorf("Expected: '%v', got: '%v'", ErrNotifierNotFound, err)
+	}
+}
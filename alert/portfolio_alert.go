@@ -0,0 +1,236 @@
+package alert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/stevenwilkin/treasury/state"
+	"github.com/stevenwilkin/treasury/symbol"
+)
+
+type PortfolioMetric int
+
+const (
+	PnlMetric PortfolioMetric = iota
+	PnlPercentageMetric
+	ValueMetric
+)
+
+func portfolioMetrics() []string {
+	return []string{"pnl", "pnl_percentage", "value"}
+}
+
+func (m PortfolioMetric) String() string {
+	return portfolioMetrics()[m]
+}
+
+func ParsePortfolioMetric(s string) (PortfolioMetric, error) {
+	for i, m := range portfolioMetrics() {
+		if strings.ToLower(s) == m {
+			return PortfolioMetric(i), nil
+		}
+	}
+	return PnlMetric, errors.New("Invalid metric")
+}
+
+type PortfolioAlert struct {
+	active    bool
+	state     *state.State
+	metric    PortfolioMetric
+	usd       bool
+	direction Direction
+	crosses   bool
+	level     float64
+}
+
+func (a *PortfolioAlert) currency() string {
+	if a.usd {
+		return "USD"
+	}
+
+	return "THB"
+}
+
+func (a *PortfolioAlert) format(v float64) string {
+	if a.metric == PnlPercentageMetric {
+		return fmt.Sprintf("%.2f%%", v)
+	}
+
+	return fmt.Sprintf("%.2f %s", v, a.currency())
+}
+
+func (a *PortfolioAlert) value(s *state.Snapshot) (float64, bool) {
+	if a.metric == PnlPercentageMetric {
+		return s.PnlPercentage(), true
+	}
+
+	rate := 1.0
+	if a.usd {
+		if rate = s.Symbol(symbol.USDTHB); rate <= 0 {
+			return 0, false
+		}
+	}
+
+	if a.metric == ValueMetric {
+		return s.TotalValue() / rate, true
+	}
+
+	return s.Pnl() / rate, true
+}
+
+func (a *PortfolioAlert) Description() string {
+	name := "PnL"
+	if a.metric == ValueMetric {
+		name = "Value"
+	}
+
+	if a.direction == Crosses {
+		return fmt.Sprintf("%s alert at %s", name, a.format(a.level))
+	}
+
+	return fmt.Sprintf("%s alert %s %s", name, a.direction, a.format(a.level))
+}
+
+func (a *PortfolioAlert) Message() string {
+	s := a.state.Snapshot()
+
+	rate := 1.0
+	if a.usd {
+		rate = s.Symbol(symbol.USDTHB)
+	}
+
+	return fmt.Sprintf("Value: %.2f %s PnL: %.2f %s (%.2f%%)",
+		s.TotalValue()/rate, a.currency(),
+		s.Pnl()/rate, a.currency(),
+		s.PnlPercentage())
+}
+
+func (a *PortfolioAlert) Active() bool {
+	return a.active
+}
+
+func (a *PortfolioAlert) Priority() bool {
+	return false
+}
+
+func (a *PortfolioAlert) Activate() {
+	if a.crosses {
+		a.direction = Crosses
+	}
+	a.active = true
+}
+
+func (a *PortfolioAlert) Deactivate() {
+	a.active = false
+}
+
+func (a *PortfolioAlert) Value() float64 {
+	value, _ := a.value(a.state.Snapshot())
+	return value
+}
+
+type portfolioParams struct {
+	Metric    string
+	Currency  string
+	Direction string
+	Value     float64
+}
+
+func (a *PortfolioAlert) Type() string {
+	return "portfolio"
+}
+
+func (a *PortfolioAlert) Params() interface{} {
+	return portfolioParams{
+		Metric:    a.metric.String(),
+		Currency:  strings.ToLower(a.currency()),
+		Direction: a.direction.String(),
+		Value:     a.level}
+}
+
+func (a *PortfolioAlert) Check() bool {
+	s := a.state.Snapshot()
+
+	if len(s.ValueWarnings()) > 0 {
+		return false
+	}
+
+	value, ok := a.value(s)
+	if !ok {
+		return false
+	}
+
+	if a.direction == Crosses {
+		if value < a.level {
+			a.direction = Above
+		} else {
+			a.direction = Below
+		}
+	}
+
+	if a.direction == Above {
+		return value >= a.level
+	} else {
+		return value <= a.level
+	}
+}
+
+func (a *PortfolioAlert) Cleared(margin float64) bool {
+	s := a.state.Snapshot()
+
+	if len(s.ValueWarnings()) > 0 {
+		return false
+	}
+
+	value, ok := a.value(s)
+	if !ok {
+		return false
+	}
+
+	if a.direction == Above {
+		return value < a.level-margin
+	} else {
+		return value > a.level+margin
+	}
+}
+
+func NewPortfolioAlert(s *state.State, m PortfolioMetric, usd bool, d Direction, level float64) *PortfolioAlert {
+	return &PortfolioAlert{
+		active:    true,
+		state:     s,
+		metric:    m,
+		usd:       usd,
+		direction: d,
+		crosses:   d == Crosses,
+		level:     level}
+}
+
+func (a *Alerter) AddPortfolioAlert(m PortfolioMetric, usd bool, d Direction, level float64) int {
+	alert := NewPortfolioAlert(a.state, m, usd, d, level)
+	return a.AddAlert(alert)
+}
+
+func init() {
+	Register("portfolio", func(s *state.State, b json.RawMessage) (Alert, error) {
+		var p portfolioParams
+		if err := json.Unmarshal(b, &p); err != nil {
+			return nil, err
+		}
+
+		m, err := ParsePortfolioMetric(p.Metric)
+		if err != nil {
+			return nil, err
+		}
+
+		d, err := ParseDirection(p.Direction)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewPortfolioAlert(s, m, p.Currency == "usd", d, p.Value), nil
+	})
+}
+
+var _ PersistentAlert = &PortfolioAlert{}
@@ -0,0 +1,94 @@
+package alert
+
+import (
+	"testing"
+
+	"github.com/stevenwilkin/treasury/asset"
+	"github.com/stevenwilkin/treasury/state"
+	"github.com/stevenwilkin/treasury/symbol"
+	"github.com/stevenwilkin/treasury/venue"
+)
+
+func portfolioState() *state.State {
+	s := state.NewState()
+	s.SetSymbol(symbol.BTCTHB, 1000000)
+	s.SetSymbol(symbol.USDTHB, 35)
+	s.SetAsset(venue.Ledger, asset.BTC, 10)
+	s.SetCost(8000000)
+
+	return s
+}
+
+func TestPortfolioAlertDescription(t *testing.T) {
+	tests := []struct {
+		alert    *PortfolioAlert
+		expected string
+	}{
+		{NewPortfolioAlert(nil, PnlPercentageMetric, false, Below, -5),
+			"PnL alert below -5.00%"},
+		{NewPortfolioAlert(nil, ValueMetric, false, Above, 10000000),
+			"Value alert above 10000000.00 THB"},
+		{NewPortfolioAlert(nil, PnlMetric, true, Crosses, 1000),
+			"PnL alert at 1000.00 USD"}}
+
+	for _, test := range tests {
+		if test.alert.Description() != test.expected {
+			t.Errorf("Expected: '%s', got: '%s'",
+				test.expected, test.alert.Description())
+		}
+	}
+}
+
+func TestPortfolioAlertMessage(t *testing.T) {
+	alert := NewPortfolioAlert(portfolioState(), PnlMetric, false, Above, 0)
+
+	expected := "Value: 10000000.00 THB PnL: 2000000.00 THB (25.00%)"
+	if alert.Message() != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Message())
+	}
+}
+
+func TestPortfolioAlertDeactivate(t *testing.T) {
+	alert := NewPortfolioAlert(nil, PnlMetric, false, Above, 0)
+
+	if !alert.Active() {
+		t.Error("Alert should be active")
+	}
+
+	alert.Deactivate()
+
+	if alert.Active() {
+		t.Error("Alert should be inactive")
+	}
+}
+
+func TestPortfolioAlertCheck(t *testing.T) {
+	s := portfolioState()
+
+	tests := []struct {
+		alert     *PortfolioAlert
+		triggered bool
+	}{
+		{NewPortfolioAlert(s, PnlPercentageMetric, false, Below, -5), false},
+		{NewPortfolioAlert(s, PnlPercentageMetric, false, Above, 20), true},
+		{NewPortfolioAlert(s, ValueMetric, false, Above, 10000000), true},
+		{NewPortfolioAlert(s, ValueMetric, true, Above, 300000), false},
+		{NewPortfolioAlert(s, ValueMetric, true, Below, 300000), true},
+		{NewPortfolioAlert(s, PnlMetric, false, Crosses, 3000000), false}}
+
+	for i, test := range tests {
+		if test.alert.Check() != test.triggered {
+			t.Errorf("Unexpected result for alert %d: %s", i, test.alert.Description())
+		}
+	}
+}
+
+func TestPortfolioAlertCheckMissingPrice(t *testing.T) {
+	s := state.NewState()
+	s.SetAsset(venue.Ledger, asset.BTC, 10)
+	alert := NewPortfolioAlert(s, ValueMetric, false, Below, 1)
+
+	if alert.Check() {
+		t.Error("Alert should not be triggered without prices")
+	}
+}
@@ -0,0 +1,209 @@
+package alert
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/stevenwilkin/treasury/state"
+	"github.com/stevenwilkin/treasury/symbol"
+)
+
+type Indicator int
+
+const (
+	THBPremium Indicator = iota
+	USDTPremium
+	CombinedPremium
+)
+
+func indicators() []string {
+	return []string{"thb", "usdt", "combined"}
+}
+
+func (i Indicator) String() string {
+	return indicators()[i]
+}
+
+func ParseIndicator(s string) (Indicator, error) {
+	for i, indicator := range indicators() {
+		if strings.ToLower(s) == indicator {
+			return Indicator(i), nil
+		}
+	}
+	return THBPremium, errors.New("Invalid indicator")
+}
+
+type PremiumAlert struct {
+	active    bool
+	state     *state.State
+	indicator Indicator
+	lower     float64
+	upper     float64
+}
+
+func (a *PremiumAlert) premiums(s *state.Snapshot) (float64, float64, float64) {
+	thb := s.THBPremium() * 100
+	usdt := s.USDTPremium() * 100
+
+	return thb, usdt, thb + usdt
+}
+
+func (a *PremiumAlert) symbols() []symbol.Symbol {
+	switch a.indicator {
+	case USDTPremium:
+		return []symbol.Symbol{symbol.USDTHB, symbol.USDTTHB}
+	case CombinedPremium:
+		return []symbol.Symbol{
+			symbol.BTCTHB, symbol.BTCUSDT, symbol.USDTTHB, symbol.USDTHB}
+	default:
+		return []symbol.Symbol{symbol.BTCTHB, symbol.BTCUSDT, symbol.USDTTHB}
+	}
+}
+
+func (a *PremiumAlert) value(s *state.Snapshot) float64 {
+	thb, usdt, combined := a.premiums(s)
+
+	switch a.indicator {
+	case USDTPremium:
+		return usdt
+	case CombinedPremium:
+		return combined
+	default:
+		return thb
+	}
+}
+
+func (a *PremiumAlert) Description() string {
+	var bounds string
+
+	switch {
+	case math.IsInf(a.lower, -1):
+		bounds = fmt.Sprintf("above %+.2f%%", a.upper)
+	case math.IsInf(a.upper, 1):
+		bounds = fmt.Sprintf("below %+.2f%%", a.lower)
+	default:
+		bounds = fmt.Sprintf("outside %+.2f%% to %+.2f%%", a.lower, a.upper)
+	}
+
+	return fmt.Sprintf("Premium alert on %s %s", a.indicator, bounds)
+}
+
+func (a *PremiumAlert) Message() string {
+	s := a.state.Snapshot()
+
+	thb, usdt, combined := a.premiums(s)
+
+	return fmt.Sprintf("Premiums: THB %+.2f%% USDT %+.2f%% Combined %+.2f%%",
+		thb, usdt, combined)
+}
+
+func (a *PremiumAlert) Active() bool {
+	return a.active
+}
+
+func (a *PremiumAlert) Priority() bool {
+	return false
+}
+
+func (a *PremiumAlert) Activate() {
+	a.active = true
+}
+
+func (a *PremiumAlert) Deactivate() {
+	a.active = false
+}
+
+func (a *PremiumAlert) Value() float64 {
+	return a.value(a.state.Snapshot())
+}
+
+type premiumParams struct {
+	Indicator string
+	Lower     *float64
+	Upper     *float64
+}
+
+func (a *PremiumAlert) Type() string {
+	return "premium"
+}
+
+func (a *PremiumAlert) Params() interface{} {
+	p := premiumParams{Indicator: a.indicator.String()}
+
+	if !math.IsInf(a.lower, -1) {
+		p.Lower = &a.lower
+	}
+
+	if !math.IsInf(a.upper, 1) {
+		p.Upper = &a.upper
+	}
+
+	return p
+}
+
+func (a *PremiumAlert) Check() bool {
+	s := a.state.Snapshot()
+
+	if len(s.SymbolWarnings(a.symbols()...)) > 0 {
+		return false
+	}
+
+	value := a.value(s)
+
+	return value <= a.lower || value >= a.upper
+}
+
+func (a *PremiumAlert) Cleared(margin float64) bool {
+	s := a.state.Snapshot()
+
+	if len(s.SymbolWarnings(a.symbols()...)) > 0 {
+		return false
+	}
+
+	value := a.value(s)
+
+	return value > a.lower+margin && value < a.upper-margin
+}
+
+func NewPremiumAlert(s *state.State, i Indicator, lower, upper float64) *PremiumAlert {
+	return &PremiumAlert{
+		active:    true,
+		state:     s,
+		indicator: i,
+		lower:     lower,
+		upper:     upper}
+}
+
+func (a *Alerter) AddPremiumAlert(i Indicator, lower, upper float64) int {
+	alert := NewPremiumAlert(a.state, i, lower, upper)
+	return a.AddAlert(alert)
+}
+
+func init() {
+	Register("premium", func(s *state.State, b json.RawMessage) (Alert, error) {
+		var p premiumParams
+		if err := json.Unmarshal(b, &p); err != nil {
+			return nil, err
+		}
+
+		i, err := ParseIndicator(p.Indicator)
+		if err != nil {
+			return nil, err
+		}
+
+		lower, upper := math.Inf(-1), math.Inf(1)
+		if p.Lower != nil {
+			lower = *p.Lower
+		}
+		if p.Upper != nil {
+			upper = *p.Upper
+		}
+
+		return NewPremiumAlert(s, i, lower, upper), nil
+	})
+}
+
+var _ PersistentAlert = &PremiumAlert{}
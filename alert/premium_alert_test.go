@@ -0,0 +1,121 @@
+package alert
+
+import (
+	"math"
+	"testing"
+	"time"
+
+	"github.com/stevenwilkin/treasury/state"
+	"github.com/stevenwilkin/treasury/symbol"
+)
+
+func premiumState() *state.State {
+	s := state.NewState()
+	s.SetSymbol(symbol.BTCUSDT, 20000)
+	s.SetSymbol(symbol.USDTHB, 35)
+	s.SetSymbol(symbol.USDTTHB, 35.35)
+	s.SetSymbol(symbol.BTCTHB, 20000*35.35*1.02)
+
+	return s
+}
+
+func TestPremiumAlertDescription(t *testing.T) {
+	tests := []struct {
+		alert    *PremiumAlert
+		expected string
+	}{
+		{NewPremiumAlert(nil, THBPremium, -1, 3),
+			"Premium alert on thb outside -1.00% to +3.00%"},
+		{NewPremiumAlert(nil, USDTPremium, math.Inf(-1), 2),
+			"Premium alert on usdt above +2.00%"},
+		{NewPremiumAlert(nil, CombinedPremium, 0.5, math.Inf(1)),
+			"Premium alert on combined below +0.50%"}}
+
+	for _, test := range tests {
+		if test.alert.Description() != test.expected {
+			t.Errorf("Expected: '%s', got: '%s'",
+				test.expected, test.alert.Description())
+		}
+	}
+}
+
+func TestPremiumAlertMessage(t *testing.T) {
+	alert := NewPremiumAlert(premiumState(), THBPremium, -1, 3)
+
+	expected := "Premiums: THB +2.00% USDT +1.00% Combined +3.00%"
+	if alert.Message() != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Message())
+	}
+}
+
+func TestPremiumAlertDeactivate(t *testing.T) {
+	alert := NewPremiumAlert(nil, THBPremium, -1, 3)
+
+	if !alert.Active() {
+		t.Error("Alert should be active")
+	}
+
+	alert.Deactivate()
+
+	if alert.Active() {
+		t.Error("Alert should be inactive")
+	}
+}
+
+func TestPremiumAlertCheck(t *testing.T) {
+	s := premiumState()
+
+	tests := []struct {
+		alert     *PremiumAlert
+		triggered bool
+	}{
+		{NewPremiumAlert(s, THBPremium, -1, 3), false},
+		{NewPremiumAlert(s, THBPremium, -1, 1.5), true},
+		{NewPremiumAlert(s, USDTPremium, 1.5, math.Inf(1)), true},
+		{NewPremiumAlert(s, USDTPremium, 0.5, math.Inf(1)), false},
+		{NewPremiumAlert(s, CombinedPremium, math.Inf(-1), 2.5), true},
+		{NewPremiumAlert(s, CombinedPremium, math.Inf(-1), 3.5), false}}
+
+	for i, test := range tests {
+		if test.alert.Check() != test.triggered {
+			t.Errorf("Unexpected result for alert %d: %s", i, test.alert.Description())
+		}
+	}
+}
+
+func TestPremiumAlertCheckMissingPrice(t *testing.T) {
+	alert := NewPremiumAlert(state.NewState(), THBPremium, 0.5, 3)
+
+	if alert.Check() {
+		t.Error("Alert should not be triggered without prices")
+	}
+}
+
+func TestPremiumAlertCheckStalePrice(t *testing.T) {
+	s := premiumState()
+	s.SetStaleAfter(time.Millisecond)
+	s.SetSymbolFrom(symbol.BTCTHB, 20000*35.35*1.02, "bitkub")
+
+	thb := NewPremiumAlert(s, THBPremium, -1, 1.5)
+	usdt := NewPremiumAlert(s, USDTPremium, 1.5, math.Inf(1))
+
+	time.Sleep(2 * time.Millisecond)
+
+	if thb.Check() {
+		t.Error("Alert should not be triggered by a stale price")
+	}
+
+	if !usdt.Check() {
+		t.Error("Alert should not be affected by prices it does not use")
+	}
+}
+
+func TestParseIndicator(t *testing.T) {
+	if i, err := ParseIndicator("USDT"); err != nil || i != USDTPremium {
+		t.Error("Should parse usdt")
+	}
+
+	if _, err := ParseIndicator("fake"); err == nil {
+		t.Error("Should return an error")
+	}
+}
@@ -1,74 +1,216 @@
 package alert
 
 import (
+	"encoding/json"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/stevenwilkin/treasury/state"
 	"github.com/stevenwilkin/treasury/symbol"
 )
 
-type direction int
+type Direction int
 
 const (
-	rising direction = iota
-	falling
+	Crosses Direction = iota
+	Above
+	Below
 )
 
+func directions() []string {
+	return []string{"crosses", "above", "below"}
+}
+
+func (d Direction) String() string {
+	return directions()[d]
+}
+
+func ParseDirection(s string) (Direction, error) {
+	for i, d := range directions() {
+		if strings.ToLower(s) == d {
+			return Direction(i), nil
+		}
+	}
+	return Crosses, errors.New("Invalid direction")
+}
+
 type PriceAlert struct {
 	active    bool
 	state     *state.State
 	symbol    symbol.Symbol
 	price     float64
-	direction direction
+	direction Direction
+	crosses   bool
+	expires   time.Time
+	note      string
 }
 
 func (a *PriceAlert) Description() string {
-	return fmt.Sprintf("Price alert at %s %.2f", a.symbol, a.price)
+	description := fmt.Sprintf("Price alert at %s %.2f", a.symbol, a.price)
+	if a.direction != Crosses {
+		description = fmt.Sprintf(
+			"Price alert at %s %s %.2f", a.symbol, a.direction, a.price)
+	}
+
+	if !a.expires.IsZero() {
+		description += fmt.Sprintf(" until %s", a.expires.Format("2006-01-02 15:04"))
+	}
+
+	if a.note != "" {
+		description += fmt.Sprintf(" - %s", a.note)
+	}
+
+	return description
 }
 
 func (a *PriceAlert) Message() string {
-	return fmt.Sprintf("%s has reached %.2f", a.symbol, a.price)
+	message := fmt.Sprintf("%s has reached %.2f", a.symbol, a.price)
+
+	if a.note != "" {
+		message += fmt.Sprintf(" - %s", a.note)
+	}
+
+	return message
 }
 
 func (a *PriceAlert) Active() bool {
-	return a.active
+	return a.active && (a.expires.IsZero() || time.Now().Before(a.expires))
 }
 
 func (a *PriceAlert) Priority() bool {
 	return false
 }
 
+func (a *PriceAlert) Activate() {
+	if a.crosses {
+		a.direction = Crosses
+	}
+	a.active = true
+}
+
 func (a *PriceAlert) Deactivate() {
 	a.active = false
 }
 
+func (a *PriceAlert) Value() float64 {
+	return a.state.Symbol(a.symbol)
+}
+
+type priceParams struct {
+	Symbol    string
+	Direction string
+	Price     float64
+	Expires   time.Time
+	Note      string
+}
+
+func (a *PriceAlert) Type() string {
+	return "price"
+}
+
+func (a *PriceAlert) Params() interface{} {
+	return priceParams{
+		Symbol:    a.symbol.String(),
+		Direction: a.direction.String(),
+		Price:     a.price,
+		Expires:   a.expires,
+		Note:      a.note}
+}
+
 func (a *PriceAlert) Check() bool {
-	currentPrice := a.state.Symbol(a.symbol)
+	s := a.state.Snapshot()
+
+	if len(s.SymbolWarnings(a.symbol)) > 0 {
+		return false
+	}
 
-	if a.direction == rising {
+	currentPrice := s.Symbol(a.symbol)
+	if currentPrice <= 0 {
+		return false
+	}
+
+	if a.direction == Crosses {
+		if currentPrice < a.price {
+			a.direction = Above
+		} else {
+			a.direction = Below
+		}
+	}
+
+	if a.direction == Above {
 		return currentPrice >= a.price
 	} else {
 		return currentPrice <= a.price
 	}
 }
 
-func NewPriceAlert(s *state.State, sym symbol.Symbol, price float64) *PriceAlert {
-	d := rising
-	if s.Symbol(sym) > price {
-		d = falling
+func (a *PriceAlert) Cleared(margin float64) bool {
+	s := a.state.Snapshot()
+
+	if len(s.SymbolWarnings(a.symbol)) > 0 {
+		return false
 	}
 
+	currentPrice := s.Symbol(a.symbol)
+	if currentPrice <= 0 {
+		return false
+	}
+
+	if a.direction == Above {
+		return currentPrice < a.price-margin
+	} else {
+		return currentPrice > a.price+margin
+	}
+}
+
+func (a *PriceAlert) WithExpiry(expires time.Time) *PriceAlert {
+	a.expires = expires
+	return a
+}
+
+func (a *PriceAlert) WithNote(note string) *PriceAlert {
+	a.note = note
+	return a
+}
+
+func NewPriceAlert(s *state.State, sym symbol.Symbol, d Direction, price float64) *PriceAlert {
 	return &PriceAlert{
 		active:    true,
 		state:     s,
 		symbol:    sym,
 		price:     price,
-		direction: d}
+		direction: d,
+		crosses:   d == Crosses}
+}
+
+func (a *Alerter) AddPriceAlert(sym symbol.Symbol, d Direction, price float64, expires time.Time, note string) int {
+	alert := NewPriceAlert(a.state, sym, d, price).WithExpiry(expires).WithNote(note)
+	return a.AddAlert(alert)
 }
 
-func (a *Alerter) AddPriceAlert(price float64) {
-	alert := NewPriceAlert(a.state, symbol.BTCUSDT, price)
-	a.AddAlert(alert)
+func init() {
+	Register("price", func(s *state.State, b json.RawMessage) (Alert, error) {
+		var p priceParams
+		if err := json.Unmarshal(b, &p); err != nil {
+			return nil, err
+		}
+
+		sym, err := symbol.FromString(p.Symbol)
+		if err != nil {
+			return nil, err
+		}
+
+		d, err := ParseDirection(p.Direction)
+		if err != nil {
+			return nil, err
+		}
+
+		return NewPriceAlert(s, sym, d, p.Price).
+			WithExpiry(p.Expires).
+			WithNote(p.Note), nil
+	})
 }
 
-var _ Alert = &PriceAlert{}
+var _ PersistentAlert = &PriceAlert{}
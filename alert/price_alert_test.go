@@ -2,6 +2,7 @@ package alert
 
 import (
 	"testing"
+	"time"
 
 	"github.com/stevenwilkin/treasury/state"
 	"github.com/stevenwilkin/treasury/symbol"
@@ -9,7 +10,7 @@ import (
 
 func TestDescription(t *testing.T) {
 	state := state.NewState()
-	alert := NewPriceAlert(state, symbol.BTCTHB, 300000)
+	alert := NewPriceAlert(state, symbol.BTCTHB, Crosses, 300000)
 
 	expected := "Price alert at BTCTHB 300000.00"
 
@@ -20,7 +21,7 @@ func TestDescription(t *testing.T) {
 
 func TestMessage(t *testing.T) {
 	state := state.NewState()
-	alert := NewPriceAlert(state, symbol.BTCTHB, 300000)
+	alert := NewPriceAlert(state, symbol.BTCTHB, Crosses, 300000)
 
 	expected := "BTCTHB has reached 300000.00"
 
@@ -31,7 +32,7 @@ func TestMessage(t *testing.T) {
 
 func TestDeactivate(t *testing.T) {
 	state := state.NewState()
-	alert := NewPriceAlert(state, symbol.BTCTHB, 300000)
+	alert := NewPriceAlert(state, symbol.BTCTHB, Crosses, 300000)
 
 	if !alert.Active() {
 		t.Error("Alert should be active")
@@ -47,7 +48,7 @@ func TestDeactivate(t *testing.T) {
 func TestCheckOnRisingPrice(t *testing.T) {
 	state := state.NewState()
 	state.SetSymbol(symbol.BTCTHB, 200000)
-	alert := NewPriceAlert(state, symbol.BTCTHB, 300000)
+	alert := NewPriceAlert(state, symbol.BTCTHB, Crosses, 300000)
 
 	if alert.Check() {
 		t.Error("Alert should not be triggered")
@@ -63,7 +64,7 @@ func TestCheckOnRisingPrice(t *testing.T) {
 func TestCheckOnFallingPrice(t *testing.T) {
 	state := state.NewState()
 	state.SetSymbol(symbol.BTCTHB, 400000)
-	alert := NewPriceAlert(state, symbol.BTCTHB, 300000)
+	alert := NewPriceAlert(state, symbol.BTCTHB, Crosses, 300000)
 
 	if alert.Check() {
 		t.Error("Alert should not be triggered")
@@ -75,3 +76,125 @@ func TestCheckOnFallingPrice(t *testing.T) {
 		t.Error("Alert should be triggered")
 	}
 }
+
+func TestCheckWithoutPriceDoesNotFixDirection(t *testing.T) {
+	state := state.NewState()
+	alert := NewPriceAlert(state, symbol.BTCTHB, Crosses, 300000)
+
+	if alert.Check() {
+		t.Error("Alert should not be triggered without a price")
+	}
+
+	state.SetSymbol(symbol.BTCTHB, 400000)
+
+	if alert.Check() {
+		t.Error("Alert should not be triggered")
+	}
+
+	state.SetSymbol(symbol.BTCTHB, 200000)
+
+	if !alert.Check() {
+		t.Error("Alert should be triggered")
+	}
+}
+
+func TestCheckAbove(t *testing.T) {
+	state := state.NewState()
+	state.SetSymbol(symbol.USDTHB, 40)
+	alert := NewPriceAlert(state, symbol.USDTHB, Above, 30)
+
+	if !alert.Check() {
+		t.Error("Alert should be triggered")
+	}
+}
+
+func TestCheckBelow(t *testing.T) {
+	state := state.NewState()
+	state.SetSymbol(symbol.USDTHB, 20)
+	alert := NewPriceAlert(state, symbol.USDTHB, Below, 30)
+
+	if !alert.Check() {
+		t.Error("Alert should be triggered")
+	}
+}
+
+func TestDescriptionWithDirectionExpiryAndNote(t *testing.T) {
+	expires := time.Date(2030, 1, 2, 3, 4, 0, 0, time.Local)
+	alert := NewPriceAlert(nil, symbol.USDTHB, Below, 30).
+		WithExpiry(expires).
+		WithNote("buy")
+
+	expected := "Price alert at USDTHB below 30.00 until 2030-01-02 03:04 - buy"
+
+	if alert.Description() != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Description())
+	}
+
+	expected = "USDTHB has reached 30.00 - buy"
+
+	if alert.Message() != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Message())
+	}
+}
+
+func TestExpiredAlertIsInactive(t *testing.T) {
+	alert := NewPriceAlert(nil, symbol.USDTHB, Below, 30).
+		WithExpiry(time.Now().Add(-time.Minute))
+
+	if alert.Active() {
+		t.Error("Expired alert should be inactive")
+	}
+}
+
+func TestParseDirection(t *testing.T) {
+	if d, err := ParseDirection("Above"); err != nil || d != Above {
+		t.Error("Should parse above")
+	}
+
+	if d, err := ParseDirection("below"); err != nil || d != Below {
+		t.Error("Should parse below")
+	}
+
+	if _, err := ParseDirection("sideways"); err == nil {
+		t.Error("Should return an error")
+	}
+}
+
+func TestActivateResetsCrossingDirection(t *testing.T) {
+	state := state.NewState()
+	state.SetSymbol(symbol.BTCTHB, 200000)
+	alert := NewPriceAlert(state, symbol.BTCTHB, Crosses, 300000)
+	alert.Check()
+
+	state.SetSymbol(symbol.BTCTHB, 310000)
+	if !alert.Check() {
+		t.Fatal("Alert should trigger on rising price")
+	}
+
+	alert.Deactivate()
+	alert.Activate()
+
+	if alert.Check() {
+		t.Error("Re-armed alert should not trigger until price crosses back")
+	}
+
+	state.SetSymbol(symbol.BTCTHB, 290000)
+	if !alert.Check() {
+		t.Error("Re-armed alert should trigger on falling price")
+	}
+}
+
+func TestPriceAlertCleared(t *testing.T) {
+	state := state.NewState()
+	alert := NewPriceAlert(state, symbol.BTCTHB, Above, 300000)
+
+	state.SetSymbol(symbol.BTCTHB, 299500)
+	if alert.Cleared(1000) {
+		t.Error("Should not clear within margin")
+	}
+
+	state.SetSymbol(symbol.BTCTHB, 298000)
+	if !alert.Cleared(1000) {
+		t.Error("Should clear beyond margin")
+	}
+}
@@ -0,0 +1,25 @@
+package alert
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/stevenwilkin/treasury/state"
+)
+
+type Constructor func(*state.State, json.RawMessage) (Alert, error)
+
+var constructors = map[string]Constructor{}
+
+func Register(t string, c Constructor) {
+	constructors[t] = c
+}
+
+func Build(s *state.State, t string, params json.RawMessage) (Alert, error) {
+	c, ok := constructors[t]
+	if !ok {
+		return nil, fmt.Errorf("Unknown alert type: %s", t)
+	}
+
+	return c(s, params)
+}
@@ -1,6 +1,7 @@
 package binance
 
 import (
+	"context"
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/json"
@@ -10,8 +11,12 @@ import (
 	"net/http"
 	"net/url"
 	"strconv"
+	"strings"
 	"time"
 
+	"github.com/stevenwilkin/treasury/asset"
+	"github.com/stevenwilkin/treasury/symbol"
+
 	"github.com/gorilla/websocket"
 	log "github.com/sirupsen/logrus"
 )
@@ -84,32 +89,30 @@ func (b *Binance) doRequest(method, path string, values url.Values, sign bool) (
 	return body, nil
 }
 
-func (b *Binance) GetBalances() ([3]float64, error) {
+func (b *Binance) GetBalances() (asset.Balances, error) {
 	body, err := b.doRequest("GET", "/api/v3/account", url.Values{}, true)
 	if err != nil {
 		log.WithField("venue", "binance").Warn(err.Error())
-		return [3]float64{}, err
+		return asset.Balances{}, err
 	}
 
 	var response accountResponse
 	json.Unmarshal(body, &response)
 
-	var btc, usdt, usdc float64
-
-	for _, asset := range response.Balances {
-		switch asset.Asset {
-		case "BTC":
-			btc = asset.Total()
-		case "USDT":
-			usdt = asset.Total()
-		case "USDC":
-			usdc = asset.Total()
-		default:
+	balances := asset.Balances{asset.BTC: 0, asset.USDT: 0, asset.USDC: 0}
+
+	for _, ab := range response.Balances {
+		a, err := asset.FromString(ab.Asset)
+		if err != nil {
 			continue
 		}
+
+		if _, ok := balances[a]; ok {
+			balances[a] = ab.Total()
+		}
 	}
 
-	return [3]float64{btc, usdt, usdc}, nil
+	return balances, nil
 }
 
 func (b *Binance) subscribe(stream string) (*websocket.Conn, error) {
@@ -126,21 +129,36 @@ func (b *Binance) subscribe(stream string) (*websocket.Conn, error) {
 	return c, nil
 }
 
-func (b *Binance) Price() chan float64 {
+func (b *Binance) Price(ctx context.Context, s symbol.Symbol, report func(error)) chan float64 {
 	ch := make(chan float64)
 
-	c, err := b.subscribe("btcusdt@aggTrade")
+	c, err := b.subscribe(fmt.Sprintf("%s@aggTrade", strings.ToLower(s.String())))
 	if err != nil {
 		log.WithField("venue", "binance").Warn(err.Error())
+		report(err)
 		close(ch)
 		return ch
 	}
 
+	done := make(chan struct{})
 	go func() {
+		select {
+		case <-ctx.Done():
+			c.Close()
+		case <-done:
+		}
+	}()
+
+	go func() {
+		defer close(done)
+
 		for {
 			_, message, err := c.ReadMessage()
 			if err != nil {
-				log.WithField("venue", "binance").Warn(err.Error())
+				if ctx.Err() == nil {
+					log.WithField("venue", "binance").Warn(err.Error())
+					report(err)
+				}
 				c.Close()
 				close(ch)
 				return
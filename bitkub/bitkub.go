@@ -1,6 +1,7 @@
 package bitkub
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -43,21 +44,36 @@ func (b *Bitkub) subscribeToPrice(s symbol.Symbol) (*websocket.Conn, error) {
 	return c, nil
 }
 
-func (b *Bitkub) PriceWS(s symbol.Symbol) chan float64 {
+func (b *Bitkub) Price(ctx context.Context, s symbol.Symbol, report func(error)) chan float64 {
 	ch := make(chan float64)
 
 	c, err := b.subscribeToPrice(s)
 	if err != nil {
 		log.WithField("venue", "bitkub").Warn(err.Error())
+		report(err)
 		close(ch)
 		return ch
 	}
 
+	done := make(chan struct{})
 	go func() {
+		select {
+		case <-ctx.Done():
+			c.Close()
+		case <-done:
+		}
+	}()
+
+	go func() {
+		defer close(done)
+
 		for {
 			_, message, err := c.ReadMessage()
 			if err != nil {
-				log.WithField("venue", "bitkub").Warn(err.Error())
+				if ctx.Err() == nil {
+					log.WithField("venue", "bitkub").Warn(err.Error())
+					report(err)
+				}
 				c.Close()
 				close(ch)
 				return
@@ -13,6 +13,8 @@ import (
 	"strconv"
 	"time"
 
+	"github.com/stevenwilkin/treasury/asset"
+
 	log "github.com/sirupsen/logrus"
 )
 
@@ -128,36 +130,54 @@ func (b *Bybit) GetSize() int {
 	return size
 }
 
-func (b *Bybit) GetEquityAndLeverage() ([2]float64, error) {
+func (b *Bybit) getEquity() (float64, error) {
 	var response walletResponse
 
 	err := b.get("/v5/account/wallet-balance",
 		url.Values{"accountType": {"UNIFIED"}, "coin": {"BTC"}}, &response)
 
 	if err != nil {
-		return [2]float64{}, err
+		return 0, err
 	}
 
 	if len(response.Result.List) != 1 {
-		return [2]float64{}, errors.New("Unexpected wallet response")
+		return 0, errors.New("Unexpected wallet response")
 	}
 
 	if len(response.Result.List[0].Coin) != 1 {
-		return [2]float64{}, errors.New("Unexpected coin response")
+		return 0, errors.New("Unexpected coin response")
 	}
 
 	equity, _ := strconv.ParseFloat(response.Result.List[0].Coin[0].Equity, 64)
+
+	return equity, nil
+}
+
+func (b *Bybit) GetAccount() (asset.Balances, float64, error) {
+	equity, err := b.getEquity()
+	if err != nil {
+		log.WithField("venue", "bybit").Warn(err.Error())
+		return asset.Balances{}, 0, err
+	}
+
+	balances := asset.Balances{asset.BTC: equity}
+
 	if equity == 0 {
-		return [2]float64{}, nil
+		return balances, 0, nil
 	}
 
 	resp, err := b.positionRequest()
 	if err != nil {
 		log.WithField("venue", "bybit").Warn(err.Error())
-		return [2]float64{equity, 0}, nil
+		return asset.Balances{}, 0, err
 	}
 
 	positionValue, _ := strconv.ParseFloat(resp.Result.List[0].PositionValue, 64)
 
-	return [2]float64{equity, (positionValue / equity)}, nil
+	return balances, positionValue / equity, nil
+}
+
+func (b *Bybit) GetLeverage() (float64, error) {
+	_, leverage, err := b.GetAccount()
+	return leverage, err
 }
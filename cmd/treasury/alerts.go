@@ -1,15 +1,24 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
 	"net/url"
+	"os"
+	"time"
 
 	"github.com/spf13/cobra"
 )
 
 type alertsMessage struct {
+	Id          int
 	Active      bool
 	Description string
+	Triggered   *time.Time
+	Snoozed     *time.Time
+	Value       float64
+	Repeat      bool
+	Escalating  bool
 }
 
 var alertsCmd = &cobra.Command{
@@ -24,17 +33,187 @@ var alertsCmd = &cobra.Command{
 			if !alert.Active {
 				active = "Inactive"
 			}
-			fmt.Printf("%s - %s\n", active, alert.Description)
+
+			details := fmt.Sprintf("last %.2f", alert.Value)
+			if alert.Repeat {
+				details += ", repeating"
+			}
+			if alert.Escalating {
+				details += ", escalating"
+			}
+			if alert.Triggered != nil {
+				details += fmt.Sprintf(", triggered %s",
+					alert.Triggered.Local().Format("2006-01-02 15:04"))
+			}
+			if alert.Snoozed != nil {
+				details += fmt.Sprintf(", snoozed until %s",
+					alert.Snoozed.Local().Format("2006-01-02 15:04"))
+			}
+
+			fmt.Printf("%3d %s - %s (%s)\n", alert.Id, active, alert.Description, details)
 		}
 	},
 }
 
+var (
+	alertExpires string
+	alertNote    string
+	alertUSD     bool
+	alertLower   string
+	alertUpper   string
+	alertPercent bool
+
+	alertRepeat     bool
+	alertHysteresis string
+	alertCooldown   string
+)
+
+func addAlert(path string, values url.Values) {
+	if alertRepeat {
+		if values == nil {
+			values = url.Values{}
+		}
+		values.Set("repeat", "true")
+		values.Set("hysteresis", alertHysteresis)
+		values.Set("cooldown", alertCooldown)
+	}
+
+	var result struct {
+		Id int
+	}
+
+	if err := json.Unmarshal(post(path, values), &result); err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Alert %d\n", result.Id)
+}
+
+func parseExpiry(s string) (string, error) {
+	if s == "" {
+		return "", nil
+	}
+
+	if d, err := time.ParseDuration(s); err == nil {
+		return time.Now().Add(d).Format(time.RFC3339), nil
+	}
+
+	for _, layout := range []string{time.RFC3339, "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t.Format(time.RFC3339), nil
+		}
+	}
+
+	return "", fmt.Errorf("Invalid expiry: %s", s)
+}
+
 var alertsPriceCmd = &cobra.Command{
-	Use:   "price [value]",
+	Use:   "price [symbol] [above|below] [value]",
 	Short: "Set price alert",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 && len(args) != 3 {
+			return fmt.Errorf("accepts 1 or 3 arg(s), received %d", len(args))
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		expires, err := parseExpiry(alertExpires)
+		if err != nil {
+			fmt.Println(err)
+			os.Exit(1)
+		}
+
+		values := url.Values{
+			"value":   {args[len(args)-1]},
+			"expires": {expires},
+			"note":    {alertNote}}
+
+		if len(args) == 3 {
+			values.Set("symbol", args[0])
+			values.Set("direction", args[1])
+		}
+
+		addAlert("/alerts/price", values)
+	},
+}
+
+var alertsMoveCmd = &cobra.Command{
+	Use:   "move [symbol] [percentage] [window] [up|down|either]",
+	Short: "Set percentage move alert",
+	Args:  cobra.RangeArgs(3, 4),
+	Run: func(cmd *cobra.Command, args []string) {
+		values := url.Values{
+			"symbol":     {args[0]},
+			"percentage": {args[1]},
+			"window":     {args[2]}}
+
+		if len(args) == 4 {
+			values.Set("direction", args[3])
+		}
+
+		addAlert("/alerts/move", values)
+	},
+}
+
+var alertsExposureCmd = &cobra.Command{
+	Use:   "exposure [btc]",
+	Short: "Set exposure alert",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		post("/alerts/price", url.Values{"value": {args[0]}})
+		values := url.Values{"value": {args[0]}}
+		if alertUSD {
+			values.Set("unit", "usd")
+		}
+
+		addAlert("/alerts/exposure", values)
+	},
+}
+
+var alertsPremiumCmd = &cobra.Command{
+	Use:   "premium [thb|usdt|combined]",
+	Short: "Set premium alert",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		addAlert("/alerts/premium", url.Values{
+			"indicator": {args[0]},
+			"lower":     {alertLower},
+			"upper":     {alertUpper}})
+	},
+}
+
+func portfolioAlertValues(metric string, args []string) url.Values {
+	values := url.Values{
+		"metric":    {metric},
+		"direction": {args[0]},
+		"value":     {args[1]}}
+
+	if alertUSD {
+		values.Set("currency", "usd")
+	}
+
+	return values
+}
+
+var alertsPnlCmd = &cobra.Command{
+	Use:   "pnl [above|below] [value]",
+	Short: "Set PnL alert",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		metric := "pnl"
+		if alertPercent {
+			metric = "pnl_percentage"
+		}
+
+		addAlert("/alerts/portfolio", portfolioAlertValues(metric, args))
+	},
+}
+
+var alertsValueCmd = &cobra.Command{
+	Use:   "value [above|below] [value]",
+	Short: "Set portfolio value alert",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		addAlert("/alerts/portfolio", portfolioAlertValues("value", args))
 	},
 }
 
@@ -42,7 +221,7 @@ var alertsFundingCmd = &cobra.Command{
 	Use:   "funding",
 	Short: "Set funding alert",
 	Run: func(cmd *cobra.Command, args []string) {
-		post("/alerts/funding", nil)
+		addAlert("/alerts/funding", nil)
 	},
 }
 
@@ -51,7 +230,43 @@ var alertsLeverageCmd = &cobra.Command{
 	Short: "Set leverage alert",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		post("/alerts/leverage", url.Values{"value": {args[0]}})
+		addAlert("/alerts/leverage", url.Values{"value": {args[0]}})
+	},
+}
+
+var alertsDeleteCmd = &cobra.Command{
+	Use:   "delete [id]",
+	Short: "Delete alert",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		post("/alerts/delete", url.Values{"id": {args[0]}})
+	},
+}
+
+var alertsSnoozeCmd = &cobra.Command{
+	Use:   "snooze [id] [duration]",
+	Short: "Snooze alert",
+	Args:  cobra.ExactArgs(2),
+	Run: func(cmd *cobra.Command, args []string) {
+		post("/alerts/snooze", url.Values{"id": {args[0]}, "duration": {args[1]}})
+	},
+}
+
+var alertsRearmCmd = &cobra.Command{
+	Use:   "rearm [id]",
+	Short: "Re-arm triggered alert",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		post("/alerts/rearm", url.Values{"id": {args[0]}})
+	},
+}
+
+var alertsAckCmd = &cobra.Command{
+	Use:   "ack [id]",
+	Short: "Acknowledge escalating alert",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		post("/alerts/ack", url.Values{"id": {args[0]}})
 	},
 }
 
@@ -62,3 +277,26 @@ var alertsClearCmd = &cobra.Command{
 		post("/alerts/clear", nil)
 	},
 }
+
+func init() {
+	for _, cmd := range []*cobra.Command{
+		alertsPriceCmd, alertsMoveCmd, alertsExposureCmd, alertsPremiumCmd,
+		alertsPnlCmd, alertsValueCmd, alertsFundingCmd, alertsLeverageCmd} {
+		cmd.Flags().BoolVar(&alertRepeat, "repeat", false, "Re-arm once the condition clears")
+		cmd.Flags().StringVar(&alertHysteresis, "hysteresis", "", "Margin by which the condition must clear before re-arming")
+		cmd.Flags().StringVar(&alertCooldown, "cooldown", "", "Minimum time between repeated notifications")
+	}
+
+	alertsPriceCmd.Flags().StringVar(&alertExpires, "expires", "", "Expiry as a duration such as 24h or a time")
+	alertsPriceCmd.Flags().StringVar(&alertNote, "note", "", "Note included in the notification")
+	alertsExposureCmd.Flags().BoolVar(&alertUSD, "usd", false, "Threshold is in USD rather than BTC")
+	alertsPremiumCmd.Flags().StringVar(&alertLower, "lower", "", "Lower bound as a percentage")
+	alertsPremiumCmd.Flags().StringVar(&alertUpper, "upper", "", "Upper bound as a percentage")
+	alertsPnlCmd.Flags().BoolVar(&alertPercent, "percentage", false, "Value is a percentage of cost")
+	alertsPnlCmd.Flags().BoolVar(&alertUSD, "usd", false, "Value is in USD rather than THB")
+	alertsValueCmd.Flags().BoolVar(&alertUSD, "usd", false, "Value is in USD rather than THB")
+
+	// flags must precede the arguments so negative values are not taken as flags
+	alertsPnlCmd.Flags().SetInterspersed(false)
+	alertsValueCmd.Flags().SetInterspersed(false)
+}
@@ -0,0 +1,24 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestAlertsPnlNegativeValue(t *testing.T) {
+	if err := alertsPnlCmd.ParseFlags([]string{"--percentage", "below", "-5"}); err != nil {
+		t.Fatal(err)
+	}
+
+	if !alertPercent {
+		t.Error("Should parse flags preceding the arguments")
+	}
+
+	args := alertsPnlCmd.Flags().Args()
+	if err := alertsPnlCmd.ValidateArgs(args); err != nil {
+		t.Fatal(err)
+	}
+
+	if values := portfolioAlertValues("pnl", args); values.Get("value") != "-5" {
+		t.Errorf("Expected: '%s', got: '%s'", "-5", values.Get("value"))
+	}
+}
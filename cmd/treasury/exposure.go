@@ -7,7 +7,8 @@ import (
 )
 
 type exposureMessage struct {
-	Value float64 `json:"value"`
+	Value    float64  `json:"value"`
+	Warnings []string `json:"warnings"`
 }
 
 var exposureCmd = &cobra.Command{
@@ -18,5 +19,6 @@ var exposureCmd = &cobra.Command{
 		get("/exposure", &em)
 
 		fmt.Println(em.Value)
+		printWarnings(em.Warnings)
 	},
 }
@@ -9,10 +9,19 @@ import (
 	"github.com/spf13/cobra"
 )
 
+var verboseFeeds bool
+
 type feedsResponse struct {
 	Feeds map[string]struct {
 		Active     bool
 		LastUpdate time.Time
+		Errors     int
+		Restarts   int
+		LastError  string
+		Messages   int
+		Rate       float64
+		Backoff    time.Duration
+		NextRetry  time.Time
 	}
 }
 
@@ -64,6 +73,21 @@ var feedsCmd = &cobra.Command{
 				lastUpdate = ""
 			}
 			fmt.Printf("%-*s  %s%s\n", padding, feed, status, lastUpdate)
+
+			if !verboseFeeds {
+				continue
+			}
+
+			f := fr.Feeds[feed]
+			fmt.Printf("  Messages: %d  Rate: %.2f/s  Errors: %d  Restarts: %d\n",
+				f.Messages, f.Rate, f.Errors, f.Restarts)
+			if f.LastError != "" {
+				fmt.Printf("  Last error: %s\n", f.LastError)
+			}
+			if f.NextRetry.After(time.Now()) {
+				fmt.Printf("  Backoff: %s  Next retry: %.2fs\n",
+					f.Backoff, time.Until(f.NextRetry).Seconds())
+			}
 		}
 	},
 }
@@ -76,3 +100,7 @@ var feedsReactivateCmd = &cobra.Command{
 		post("/feeds/reactivate", url.Values{"feed": {args[0]}})
 	},
 }
+
+func init() {
+	feedsCmd.Flags().BoolVarP(&verboseFeeds, "verbose", "v", false, "Display feed metrics and errors")
+}
@@ -0,0 +1,118 @@
+package main
+
+import (
+	"fmt"
+	"math"
+	"net/url"
+	"os"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type historyMessage struct {
+	Metric string `json:"metric"`
+	Points []struct {
+		Time  time.Time `json:"time"`
+		Value float64   `json:"value"`
+	} `json:"points"`
+}
+
+var (
+	historySince     string
+	historyUntil     string
+	historyStep      string
+	historySparkline bool
+)
+
+func parseTime(value string) (string, error) {
+	if value == "" {
+		return "", nil
+	}
+
+	if d, err := time.ParseDuration(value); err == nil {
+		return time.Now().Add(-d).Format(time.RFC3339), nil
+	}
+
+	if t, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return t.Format(time.RFC3339), nil
+	}
+
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return t.Format(time.RFC3339), nil
+	}
+
+	return "", fmt.Errorf("Invalid time: %s", value)
+}
+
+func sparkline(hm historyMessage) string {
+	ticks := []rune("▁▂▃▄▅▆▇█")
+	min, max := math.Inf(1), math.Inf(-1)
+
+	for _, p := range hm.Points {
+		min = math.Min(min, p.Value)
+		max = math.Max(max, p.Value)
+	}
+
+	line := make([]rune, len(hm.Points))
+	for i, p := range hm.Points {
+		tick := 0
+		if max > min {
+			tick = int((p.Value - min) / (max - min) * float64(len(ticks)-1))
+		}
+		line[i] = ticks[tick]
+	}
+
+	return fmt.Sprintf("%f %s %f", min, string(line), max)
+}
+
+var historyCmd = &cobra.Command{
+	Use:   "history [metric]",
+	Short: "Retrieve historical values of a metric",
+	Long: `Retrieve historical values of a metric
+
+Metrics: value, cost, pnl, pnl_percentage, exposure, leverage_deribit,
+leverage_bybit, funding, a symbol such as BTCUSDT, or a venue balance such
+as deribit or nexo:usdt
+
+Times may be a duration before now such as 24h, a date or an RFC3339 time`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		v := url.Values{"metric": {args[0]}, "step": {historyStep}}
+
+		for param, value := range map[string]string{
+			"since": historySince, "until": historyUntil} {
+
+			t, err := parseTime(value)
+			if err != nil {
+				fmt.Println(err)
+				os.Exit(1)
+			}
+			v.Set(param, t)
+		}
+
+		var hm historyMessage
+		get(fmt.Sprintf("/history?%s", v.Encode()), &hm)
+
+		if len(hm.Points) == 0 {
+			fmt.Println("No data")
+			return
+		}
+
+		if historySparkline {
+			fmt.Println(sparkline(hm))
+			return
+		}
+
+		for _, p := range hm.Points {
+			fmt.Printf("%s  %f\n", p.Time.Local().Format("2006-01-02 15:04"), p.Value)
+		}
+	},
+}
+
+func init() {
+	historyCmd.Flags().StringVar(&historySince, "since", "24h", "Start of range")
+	historyCmd.Flags().StringVar(&historyUntil, "until", "", "End of range, defaults to now")
+	historyCmd.Flags().StringVar(&historyStep, "step", "", "Resolution such as 1h, defaults to every snapshot")
+	historyCmd.Flags().BoolVar(&historySparkline, "sparkline", false, "Display as a sparkline")
+}
@@ -7,8 +7,9 @@ import (
 )
 
 type indicatorsMessage struct {
-	THBPremium  float64 `json:"thb_premium"`
-	USDTPremium float64 `json:"usdt_premium"`
+	THBPremium  float64  `json:"thb_premium"`
+	USDTPremium float64  `json:"usdt_premium"`
+	Warnings    []string `json:"warnings"`
 }
 
 var indicatorsCmd = &cobra.Command{
@@ -21,5 +22,6 @@ var indicatorsCmd = &cobra.Command{
 		fmt.Printf("THB  Premium: %+.2f%%\n", im.THBPremium*100)
 		fmt.Printf("USDT Premium: %+.2f%%\n", im.USDTPremium*100)
 		fmt.Printf("Combined:     %+.2f%%\n", (im.THBPremium+im.USDTPremium)*100)
+		printWarnings(im.Warnings)
 	},
 }
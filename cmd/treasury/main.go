@@ -9,11 +9,10 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 )
 
-const (
-	socketPath = "/tmp/treasuryd.sock"
-)
+var socketPath string
 
 var client = http.Client{
 	Transport: &http.Transport{
@@ -35,23 +34,46 @@ func get(path string, result interface{}) {
 		panic(err)
 	}
 
-	json.Unmarshal(body, result)
+	if resp.StatusCode != http.StatusOK {
+		fmt.Println(strings.TrimSpace(string(body)))
+		os.Exit(1)
+	}
+
+	err = json.Unmarshal(body, result)
 	if err != nil {
 		panic(err)
 	}
 }
 
-func post(path string, values url.Values) {
+func printWarnings(warnings []string) {
+	for _, warning := range warnings {
+		fmt.Fprintf(os.Stderr, "Warning: %s\n", warning)
+	}
+}
+
+func post(path string, values url.Values) []byte {
 	resp, err := client.PostForm(fmt.Sprintf("http://unix%s", path), values)
 	if err != nil {
 		fmt.Println(err)
 		os.Exit(1)
 	}
+	defer resp.Body.Close()
+
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		panic(err)
+	}
 
 	if resp.StatusCode != http.StatusOK {
-		fmt.Println("Failed")
+		if message := strings.TrimSpace(string(body)); message != "" {
+			fmt.Println(message)
+		} else {
+			fmt.Println("Failed")
+		}
 		os.Exit(1)
 	}
+
+	return body
 }
 
 func main() {
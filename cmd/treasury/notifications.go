@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+type notificationsMessage struct {
+	Id          int
+	Notifier    string
+	Description string
+	Attempts    int
+	NextAttempt time.Time `json:"next_attempt"`
+	LastError   string    `json:"last_error"`
+	Failed      bool
+}
+
+var notificationsCmd = &cobra.Command{
+	Use:   "notifications",
+	Short: "Retrieve pending and failed notifications",
+	Run: func(cmd *cobra.Command, args []string) {
+		var nm []notificationsMessage
+		get("/notifications", &nm)
+
+		for _, n := range nm {
+			status := "Pending"
+			if n.Failed {
+				status = "Failed "
+			}
+
+			details := fmt.Sprintf("%d attempts", n.Attempts)
+			if !n.Failed && n.NextAttempt.After(time.Now()) {
+				details += fmt.Sprintf(", next retry %.0fs", time.Until(n.NextAttempt).Seconds())
+			}
+
+			fmt.Printf("%3d %s %-8s - %s (%s)\n", n.Id, status, n.Notifier, n.Description, details)
+			if n.LastError != "" {
+				fmt.Printf("    Last error: %s\n", n.LastError)
+			}
+		}
+	},
+}
+
+var notificationsRetryCmd = &cobra.Command{
+	Use:   "retry [id]",
+	Short: "Retry a failed notification",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		post("/notifications/retry", url.Values{"id": {args[0]}})
+	},
+}
@@ -7,10 +7,11 @@ import (
 )
 
 type pnlMessage struct {
-	Cost          float64 `json:"cost"`
-	Value         float64 `json:"value"`
-	Pnl           float64 `json:"pnl"`
-	PnlPercentage float64 `json:"pnl_percentage"`
+	Cost          float64  `json:"cost"`
+	Value         float64  `json:"value"`
+	Pnl           float64  `json:"pnl"`
+	PnlPercentage float64  `json:"pnl_percentage"`
+	Warnings      []string `json:"warnings"`
 }
 
 var pnlCmd = &cobra.Command{
@@ -24,6 +25,7 @@ var pnlCmd = &cobra.Command{
 		fmt.Printf("Value: %f\n", pm.Value)
 		fmt.Printf("PnL:   %f\n", pm.Pnl)
 		fmt.Printf("PnL %%: %.2f\n", pm.PnlPercentage)
+		printWarnings(pm.Warnings)
 	},
 }
 
@@ -38,5 +40,6 @@ var pnlUsdCmd = &cobra.Command{
 		fmt.Printf("Value: %f\n", pm.Value)
 		fmt.Printf("PnL:   %f\n", pm.Pnl)
 		fmt.Printf("PnL %%: %.2f\n", pm.PnlPercentage)
+		printWarnings(pm.Warnings)
 	},
 }
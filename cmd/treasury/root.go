@@ -1,15 +1,42 @@
 package main
 
 import (
+	"os"
+
+	"github.com/stevenwilkin/treasury/config"
+
 	"github.com/spf13/cobra"
 )
 
+var (
+	configPath string
+	socketFlag string
+)
+
 var rootCmd = &cobra.Command{
 	Use:   "treasury",
 	Short: "CLI interface to treasuryd",
+	PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
+		c, err := config.Load(configPath)
+		if err != nil {
+			return err
+		}
+
+		socketPath = c.SocketPath
+		if len(socketFlag) > 0 {
+			socketPath = socketFlag
+		}
+
+		return nil
+	},
 }
 
 func init() {
+	rootCmd.PersistentFlags().StringVar(
+		&configPath, "config", os.Getenv("TREASURY_CONFIG"), "Path to config file")
+	rootCmd.PersistentFlags().StringVar(
+		&socketFlag, "socket", "", "Path to treasuryd control socket")
+
 	rootCmd.AddCommand(pricesCmd)
 	rootCmd.AddCommand(assetsCmd)
 	rootCmd.AddCommand(costCmd)
@@ -22,12 +49,18 @@ func init() {
 	rootCmd.AddCommand(feedsCmd)
 	rootCmd.AddCommand(indicatorsCmd)
 	rootCmd.AddCommand(loanCmd)
+	rootCmd.AddCommand(historyCmd)
+	rootCmd.AddCommand(notificationsCmd)
 
 	assetsCmd.AddCommand(setAssetsCmd)
 	alertsCmd.AddCommand(
-		alertsPriceCmd, alertsClearCmd, alertsFundingCmd, alertsLeverageCmd)
+		alertsPriceCmd, alertsClearCmd, alertsFundingCmd, alertsLeverageCmd,
+		alertsMoveCmd, alertsExposureCmd, alertsPremiumCmd, alertsPnlCmd,
+		alertsValueCmd, alertsDeleteCmd, alertsSnoozeCmd, alertsRearmCmd,
+		alertsAckCmd)
 	pnlCmd.AddCommand(pnlUsdCmd)
 	sizeCmd.AddCommand(sizeUpdateCmd)
 	feedsCmd.AddCommand(feedsReactivateCmd)
+	notificationsCmd.AddCommand(notificationsRetryCmd)
 	loanCmd.AddCommand(loanSetCmd)
 }
@@ -1,34 +1,68 @@
 package main
 
 import (
+	"context"
+	"flag"
 	"os"
 	"os/signal"
 	"syscall"
 
+	"github.com/stevenwilkin/treasury/config"
 	"github.com/stevenwilkin/treasury/daemon"
 
 	_ "github.com/joho/godotenv/autoload"
 	log "github.com/sirupsen/logrus"
 )
 
+var (
+	configPath string
+	dataDir    string
+	socketPath string
+	wwwPort    string
+)
+
 func initLogger() {
 	if level, err := log.ParseLevel(os.Getenv("LOG_LEVEL")); err == nil {
 		log.SetLevel(level)
 	}
 }
 
-func trapSigInt() {
-	c := make(chan os.Signal, 1)
-	signal.Notify(c, os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
-	<-c
-	log.Info("Shutting down")
+func initConfig() *config.Config {
+	flag.StringVar(&configPath, "config", os.Getenv("TREASURY_CONFIG"), "Path to config file")
+	flag.StringVar(&dataDir, "data-dir", "", "Directory for state and history")
+	flag.StringVar(&socketPath, "socket", "", "Path to control socket")
+	flag.StringVar(&wwwPort, "port", "", "Port for web server")
+	flag.Parse()
+
+	c, err := config.Load(configPath)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(dataDir) > 0 {
+		c.DataDir = dataDir
+	}
+
+	if len(socketPath) > 0 {
+		c.SocketPath = socketPath
+	}
+
+	if len(wwwPort) > 0 {
+		c.WWWPort = wwwPort
+	}
+
+	return c
 }
 
 func main() {
 	initLogger()
 
-	d := daemon.NewDaemon()
-	d.Run()
+	ctx, stop := signal.NotifyContext(
+		context.Background(), os.Interrupt, syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	d := daemon.NewDaemon(initConfig())
+	d.Run(ctx)
 
-	trapSigInt()
+	log.Info("Shut down")
 }
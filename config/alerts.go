@@ -0,0 +1,122 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stevenwilkin/treasury/feed"
+	"github.com/stevenwilkin/treasury/symbol"
+)
+
+const (
+	FundingAlert  = "funding"
+	PriceAlert    = "price"
+	LeverageAlert = "leverage"
+	ExposureAlert = "exposure"
+	FeedAlert     = "feed"
+)
+
+type Alert struct {
+	Type       string        `yaml:"type"`
+	Value      float64       `yaml:"value"`
+	Symbol     string        `yaml:"symbol"`
+	Direction  string        `yaml:"direction"`
+	Unit       string        `yaml:"unit"`
+	Feed       string        `yaml:"feed"`
+	Window     time.Duration `yaml:"window"`
+	Repeat     bool          `yaml:"repeat"`
+	Hysteresis float64       `yaml:"hysteresis"`
+	Cooldown   time.Duration `yaml:"cooldown"`
+}
+
+func (c *Config) AlertFeeds(a Alert) []feed.Feed {
+	results := []feed.Feed{}
+	only, err := feed.FromString(a.Feed)
+
+	for _, f := range c.EnabledFeeds() {
+		if a.Feed == "" || (err == nil && f.Feed == only) {
+			results = append(results, f.Feed)
+		}
+	}
+
+	return results
+}
+
+func (c *Config) validatePriceAlert(a Alert) error {
+	if a.Value <= 0 {
+		return fmt.Errorf("Invalid value for %s alert: %f", a.Type, a.Value)
+	}
+
+	if a.Symbol != "" {
+		if _, err := symbol.FromString(a.Symbol); err != nil {
+			return fmt.Errorf("Invalid symbol for price alert: %s", a.Symbol)
+		}
+	}
+
+	switch a.Direction {
+	case "", "above", "below":
+	default:
+		return fmt.Errorf("Invalid direction for price alert: %s", a.Direction)
+	}
+
+	return nil
+}
+
+func (c *Config) validateFeedAlert(a Alert) error {
+	if a.Window < 0 {
+		return fmt.Errorf("Invalid window for feed alert: %s", a.Window)
+	}
+
+	if a.Feed == "" {
+		return nil
+	}
+
+	if _, err := feed.FromString(a.Feed); err != nil {
+		return fmt.Errorf("Invalid feed for feed alert: %s", a.Feed)
+	}
+
+	if len(c.AlertFeeds(a)) == 0 {
+		return fmt.Errorf("Feed alert for feed that is not enabled: %s", a.Feed)
+	}
+
+	return nil
+}
+
+func (c *Config) validateAlerts() error {
+	for _, a := range c.Alerts {
+		if a.Hysteresis < 0 {
+			return fmt.Errorf("Invalid hysteresis for %s alert: %f", a.Type, a.Hysteresis)
+		}
+
+		if a.Cooldown < 0 {
+			return fmt.Errorf("Invalid cooldown for %s alert: %s", a.Type, a.Cooldown)
+		}
+
+		switch a.Type {
+		case FundingAlert:
+		case FeedAlert:
+			if err := c.validateFeedAlert(a); err != nil {
+				return err
+			}
+		case PriceAlert:
+			if err := c.validatePriceAlert(a); err != nil {
+				return err
+			}
+		case LeverageAlert:
+			if a.Value <= 0 {
+				return fmt.Errorf("Invalid value for %s alert: %f", a.Type, a.Value)
+			}
+		case ExposureAlert:
+			if a.Value <= 0 {
+				return fmt.Errorf("Invalid value for %s alert: %f", a.Type, a.Value)
+			}
+			if a.Unit != "" && a.Unit != "btc" && a.Unit != "usd" {
+				return fmt.Errorf("Invalid unit for exposure alert: %s", a.Unit)
+			}
+		default:
+			return fmt.Errorf("Invalid alert type: %s", a.Type)
+		}
+	}
+
+	return nil
+}
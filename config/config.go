@@ -0,0 +1,114 @@
+package config
+
+import (
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+type Config struct {
+	DataDir         string           `yaml:"data_dir"`
+	SocketPath      string           `yaml:"socket_path"`
+	WWWPort         string           `yaml:"www_port"`
+	StaleAfter      time.Duration    `yaml:"stale_after"`
+	ReactivateAfter time.Duration    `yaml:"reactivate_after"`
+	NotifyAttempts  int              `yaml:"notify_attempts"`
+	Venues          map[string]Venue `yaml:"venues"`
+	Feeds           []Feed           `yaml:"feeds"`
+	Alerts          []Alert          `yaml:"alerts"`
+	Escalation      []EscalationStep `yaml:"escalation"`
+	Twilio          Twilio           `yaml:"twilio"`
+}
+
+func Default() *Config {
+	return &Config{
+		DataDir:        "/var/lib/treasuryd",
+		SocketPath:     "/tmp/treasuryd.sock",
+		WWWPort:        "8080",
+		StaleAfter:     5 * time.Minute,
+		NotifyAttempts: 10}
+}
+
+func (c *Config) LoadFile(path string) error {
+	b, err := ioutil.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	return yaml.Unmarshal(b, c)
+}
+
+func (c *Config) LoadEnv() {
+	env := map[string]*string{
+		"TREASURY_DATA_DIR": &c.DataDir,
+		"TREASURY_SOCKET":   &c.SocketPath,
+		"WWW_PORT":          &c.WWWPort}
+
+	for name, value := range env {
+		if v := os.Getenv(name); len(v) > 0 {
+			*value = v
+		}
+	}
+}
+
+func (c *Config) StatePath() string {
+	return filepath.Join(c.DataDir, "state.json")
+}
+
+func (c *Config) HistoryPath() string {
+	return filepath.Join(c.DataDir, "history")
+}
+
+func (c *Config) OutboxPath() string {
+	return filepath.Join(c.DataDir, "outbox.json")
+}
+
+func Load(path string) (*Config, error) {
+	c := Default()
+
+	if path != "" {
+		if err := c.LoadFile(path); err != nil {
+			return nil, err
+		}
+	}
+
+	c.LoadEnv()
+
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+
+	return c, nil
+}
+
+func (c *Config) Validate() error {
+	if c.StaleAfter <= 0 {
+		return errors.New("Invalid stale_after")
+	}
+
+	if c.ReactivateAfter < 0 {
+		return errors.New("Invalid reactivate_after")
+	}
+
+	if c.NotifyAttempts <= 0 {
+		return errors.New("Invalid notify_attempts")
+	}
+
+	if err := c.validateVenues(); err != nil {
+		return err
+	}
+
+	if err := c.validateFeeds(); err != nil {
+		return err
+	}
+
+	if err := c.validateAlerts(); err != nil {
+		return err
+	}
+
+	return c.validateEscalation()
+}
@@ -0,0 +1,240 @@
+package config
+
+import (
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stevenwilkin/treasury/feed"
+)
+
+func writeConfig(t *testing.T, contents string) string {
+	dir, err := ioutil.TempDir("", "config")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	path := filepath.Join(dir, "treasuryd.yml")
+	if err := ioutil.WriteFile(path, []byte(contents), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	return path
+}
+
+func TestDefault(t *testing.T) {
+	c, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.StatePath() != "/var/lib/treasuryd/state.json" {
+		t.Errorf("Unexpected state path %s", c.StatePath())
+	}
+
+	if c.OutboxPath() != "/var/lib/treasuryd/outbox.json" {
+		t.Errorf("Unexpected outbox path %s", c.OutboxPath())
+	}
+
+	if c.SocketPath != "/tmp/treasuryd.sock" {
+		t.Errorf("Unexpected socket path %s", c.SocketPath)
+	}
+}
+
+func TestLoadFile(t *testing.T) {
+	path := writeConfig(t, "data_dir: /srv/paper\nsocket_path: /run/paper.sock\n")
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.DataDir != "/srv/paper" || c.SocketPath != "/run/paper.sock" {
+		t.Error("Should load values from file")
+	}
+
+	if c.WWWPort != "8080" {
+		t.Error("Should keep defaults for missing values")
+	}
+}
+
+func TestLoadMissingFile(t *testing.T) {
+	if _, err := Load("/nonexistent/treasuryd.yml"); err == nil {
+		t.Error("Should return an error")
+	}
+}
+
+func TestEnvOverridesFile(t *testing.T) {
+	path := writeConfig(t, "data_dir: /srv/paper\n")
+	os.Setenv("TREASURY_DATA_DIR", "/srv/test")
+	defer os.Unsetenv("TREASURY_DATA_DIR")
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.DataDir != "/srv/test" {
+		t.Errorf("Unexpected data dir %s", c.DataDir)
+	}
+}
+
+func TestDefaultFeeds(t *testing.T) {
+	c, _ := Load("")
+
+	if len(c.EnabledFeeds()) != 9 {
+		t.Errorf("Expected 9 feeds, got %d", len(c.EnabledFeeds()))
+	}
+}
+
+func TestDisabledVenueRemovesDefaultFeeds(t *testing.T) {
+	path := writeConfig(t, "venues:\n  bybit:\n    enabled: false\n")
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if c.VenueEnabled(Bybit) {
+		t.Error("Bybit should be disabled")
+	}
+
+	for _, f := range c.EnabledFeeds() {
+		if f.Venue == Bybit {
+			t.Errorf("Should not enable feed %s", f.Name)
+		}
+	}
+}
+
+func TestFeeds(t *testing.T) {
+	path := writeConfig(t, `
+feeds:
+  - feed: btcusdt
+  - feed: USDTHB
+    venue: xe
+    interval: 5s
+`)
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	feeds := c.EnabledFeeds()
+	if len(feeds) != 2 {
+		t.Fatalf("Expected 2 feeds, got %d", len(feeds))
+	}
+
+	if feeds[0].Venue != Binance || feeds[0].Interval != time.Second {
+		t.Error("Should apply default venue and interval")
+	}
+
+	if feeds[1].Interval != 5*time.Second {
+		t.Errorf("Unexpected interval %s", feeds[1].Interval)
+	}
+}
+
+func TestInvalidConfig(t *testing.T) {
+	tests := []string{
+		"venues:\n  kraken: {}\n",
+		"feeds:\n  - feed: fake\n",
+		"feeds:\n  - feed: btcusdt\n    venue: bitkub\n",
+		"feeds:\n  - feed: btcusdt\n  - feed: btcusdt\n",
+		"feeds:\n  - feed: btcusdt\n    interval: 5s\n",
+		"feeds:\n  - feed: deribit\n    interval: 5s\n",
+		"venues:\n  binance:\n    enabled: false\nfeeds:\n  - feed: btcusdt\n",
+		"alerts:\n  - type: fake\n",
+		"alerts:\n  - type: price\n",
+		"alerts:\n  - type: exposure\n    value: 1\n    unit: eur\n",
+		"alerts:\n  - type: price\n    value: 1\n    symbol: fake\n",
+		"alerts:\n  - type: price\n    value: 1\n    direction: sideways\n",
+		"alerts:\n  - type: feed\n    feed: fake\n",
+		"alerts:\n  - type: feed\n    window: -1s\n",
+		"alerts:\n  - type: funding\n    repeat: true\n    hysteresis: -1\n",
+		"alerts:\n  - type: funding\n    repeat: true\n    cooldown: -1s\n",
+		"feeds:\n  - feed: btcusdt\nalerts:\n  - type: feed\n    feed: usdthb\n",
+		"stale_after: 0s\n",
+		"reactivate_after: -1s\n",
+		"notify_attempts: 0\n",
+		"escalation:\n  - notifier: pager\n",
+		"escalation:\n  - notifier: twilio\n    after: -1m\n",
+		"escalation:\n  - notifier: telegram\n    to: \"+440000\"\n",
+		"twilio:\n  loop: -1\n"}
+
+	for _, contents := range tests {
+		if _, err := Load(writeConfig(t, contents)); err == nil {
+			t.Errorf("Should return an error for:\n%s", contents)
+		}
+	}
+}
+
+func TestAlertFeeds(t *testing.T) {
+	c, err := Load(writeConfig(t,
+		"feeds:\n  - feed: btcusdt\n  - feed: usdthb\n"+
+			"alerts:\n  - type: feed\n  - type: feed\n    feed: usdthb\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if feeds := c.AlertFeeds(c.Alerts[0]); len(feeds) != 2 {
+		t.Errorf("Expected 2 feeds, got %d", len(feeds))
+	}
+
+	feeds := c.AlertFeeds(c.Alerts[1])
+	if len(feeds) != 1 || feeds[0] != feed.USDTHB {
+		t.Errorf("Unexpected feeds %v", feeds)
+	}
+}
+
+func TestVenueCredentials(t *testing.T) {
+	os.Setenv("BINANCE_API_KEY", "key")
+	os.Setenv("BINANCE_SUB_SECRET", "secret")
+	defer os.Unsetenv("BINANCE_API_KEY")
+	defer os.Unsetenv("BINANCE_SUB_SECRET")
+
+	path := writeConfig(t,
+		"venues:\n  binance:\n    api_secret_env: BINANCE_SUB_SECRET\n")
+
+	c, err := Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	key, secret := c.VenueCredentials(Binance)
+	if key != "key" || secret != "secret" {
+		t.Errorf("Unexpected credentials %s %s", key, secret)
+	}
+}
+
+func TestEscalation(t *testing.T) {
+	c, err := Load(writeConfig(t,
+		"escalation:\n  - notifier: telegram\n  - notifier: twilio\n    after: 5m\n"+
+			"  - notifier: twilio\n    to: \"+440000\"\n    after: 10m\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.Escalation) != 3 {
+		t.Fatalf("Expected 3 steps, got %d", len(c.Escalation))
+	}
+
+	if c.Escalation[1].After != 5*time.Minute || c.Escalation[2].To != "+440000" {
+		t.Errorf("Unexpected escalation %+v", c.Escalation)
+	}
+}
+
+func TestTwilio(t *testing.T) {
+	c, err := Load(writeConfig(t,
+		"twilio:\n  to: [\"+440000\", \"+440001\"]\n  voice: alice\n  loop: 2\n"+
+			"escalation:\n  - notifier: sms\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(c.Twilio.To) != 2 || c.Twilio.Voice != "alice" || c.Twilio.Loop != 2 {
+		t.Errorf("Unexpected twilio config %+v", c.Twilio)
+	}
+}
@@ -0,0 +1,50 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"time"
+)
+
+const (
+	TelegramNotifier = "telegram"
+	TwilioNotifier   = "twilio"
+	SmsNotifier      = "sms"
+)
+
+type Twilio struct {
+	To    []string `yaml:"to"`
+	Sms   bool     `yaml:"sms"`
+	Voice string   `yaml:"voice"`
+	Loop  int      `yaml:"loop"`
+}
+
+type EscalationStep struct {
+	Notifier string        `yaml:"notifier"`
+	To       string        `yaml:"to"`
+	After    time.Duration `yaml:"after"`
+}
+
+func (c *Config) validateEscalation() error {
+	if c.Twilio.Loop < 0 {
+		return fmt.Errorf("Invalid loop for twilio: %d", c.Twilio.Loop)
+	}
+
+	for _, s := range c.Escalation {
+		if s.After < 0 {
+			return fmt.Errorf("Invalid after for %s escalation: %s", s.Notifier, s.After)
+		}
+
+		switch s.Notifier {
+		case TelegramNotifier:
+			if s.To != "" {
+				return errors.New("Recipient not supported for telegram escalation")
+			}
+		case TwilioNotifier, SmsNotifier:
+		default:
+			return fmt.Errorf("Invalid escalation notifier: %s", s.Notifier)
+		}
+	}
+
+	return nil
+}
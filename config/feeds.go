@@ -0,0 +1,125 @@
+package config
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stevenwilkin/treasury/feed"
+)
+
+const (
+	defaultInterval = 1 * time.Second
+)
+
+type Feed struct {
+	Name     string        `yaml:"feed"`
+	Venue    string        `yaml:"venue"`
+	Interval time.Duration `yaml:"interval"`
+	Feed     feed.Feed     `yaml:"-"`
+}
+
+func feedVenues() map[feed.Feed]string {
+	return map[feed.Feed]string{
+		feed.BTCUSDT:         Binance,
+		feed.BTCTHB:          Bitkub,
+		feed.USDTTHB:         Bitkub,
+		feed.USDCTHB:         Bitkub,
+		feed.USDTHB:          XE,
+		feed.Binance:         Binance,
+		feed.Deribit:         Deribit,
+		feed.Bybit:           Bybit,
+		feed.Funding:         Bybit,
+		feed.LeverageDeribit: Deribit}
+}
+
+func streamingFeeds() map[feed.Feed]bool {
+	return map[feed.Feed]bool{
+		feed.BTCUSDT: true,
+		feed.BTCTHB:  true,
+		feed.USDTTHB: true,
+		feed.USDCTHB: true,
+		feed.Deribit: true}
+}
+
+func defaultFeeds() []feed.Feed {
+	return []feed.Feed{
+		feed.BTCUSDT,
+		feed.Binance,
+		feed.BTCTHB,
+		feed.USDTTHB,
+		feed.USDTHB,
+		feed.Deribit,
+		feed.Bybit,
+		feed.Funding,
+		feed.LeverageDeribit}
+}
+
+func (c *Config) resolveFeed(f *Feed) error {
+	var err error
+
+	if f.Feed, err = feed.FromString(f.Name); err != nil {
+		return fmt.Errorf("Invalid feed: %s", f.Name)
+	}
+
+	source := feedVenues()[f.Feed]
+
+	if f.Venue == "" {
+		f.Venue = source
+	} else if f.Venue != source {
+		return fmt.Errorf("Feed %s is not available from %s", f.Feed, f.Venue)
+	}
+
+	if !c.VenueEnabled(f.Venue) {
+		return fmt.Errorf("Feed %s requires disabled venue %s", f.Feed, f.Venue)
+	}
+
+	if f.Interval != 0 && streamingFeeds()[f.Feed] {
+		return fmt.Errorf("Feed %s is streamed and does not take an interval", f.Feed)
+	} else if f.Interval < 0 {
+		return fmt.Errorf("Invalid interval for feed %s: %s", f.Feed, f.Interval)
+	} else if f.Interval == 0 {
+		f.Interval = defaultInterval
+	}
+
+	return nil
+}
+
+func (c *Config) EnabledFeeds() []Feed {
+	if len(c.Feeds) > 0 {
+		return c.Feeds
+	}
+
+	results := []Feed{}
+
+	for _, f := range defaultFeeds() {
+		source := feedVenues()[f]
+		if !c.VenueEnabled(source) {
+			continue
+		}
+
+		results = append(results, Feed{
+			Name:     f.String(),
+			Venue:    source,
+			Interval: defaultInterval,
+			Feed:     f})
+	}
+
+	return results
+}
+
+func (c *Config) validateFeeds() error {
+	seen := map[feed.Feed]bool{}
+
+	for i := range c.Feeds {
+		if err := c.resolveFeed(&c.Feeds[i]); err != nil {
+			return err
+		}
+
+		if seen[c.Feeds[i].Feed] {
+			return fmt.Errorf("Duplicate feed: %s", c.Feeds[i].Feed)
+		}
+		seen[c.Feeds[i].Feed] = true
+	}
+
+	return nil
+}
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	Binance = "binance"
+	Bitkub  = "bitkub"
+	Bybit   = "bybit"
+	Deribit = "deribit"
+	XE      = "xe"
+)
+
+type Venue struct {
+	Enabled      *bool  `yaml:"enabled"`
+	Testnet      bool   `yaml:"testnet"`
+	ApiKey       string `yaml:"api_key"`
+	ApiSecret    string `yaml:"api_secret"`
+	ApiKeyEnv    string `yaml:"api_key_env"`
+	ApiSecretEnv string `yaml:"api_secret_env"`
+}
+
+func venueNames() []string {
+	return []string{Binance, Bitkub, Bybit, Deribit, XE}
+}
+
+func defaultCredentialsEnv(name string) (string, string) {
+	prefix := strings.ToUpper(name)
+
+	if name == Deribit {
+		return prefix + "_API_ID", prefix + "_API_SECRET"
+	}
+
+	return prefix + "_API_KEY", prefix + "_API_SECRET"
+}
+
+func (c *Config) Venue(name string) Venue {
+	return c.Venues[name]
+}
+
+func (c *Config) VenueEnabled(name string) bool {
+	v, ok := c.Venues[name]
+	if !ok || v.Enabled == nil {
+		return true
+	}
+
+	return *v.Enabled
+}
+
+func (c *Config) VenueCredentials(name string) (string, string) {
+	v := c.Venues[name]
+	keyEnv, secretEnv := defaultCredentialsEnv(name)
+
+	if v.ApiKeyEnv != "" {
+		keyEnv = v.ApiKeyEnv
+	}
+
+	if v.ApiSecretEnv != "" {
+		secretEnv = v.ApiSecretEnv
+	}
+
+	key, secret := os.Getenv(keyEnv), os.Getenv(secretEnv)
+
+	if v.ApiKey != "" {
+		key = v.ApiKey
+	}
+
+	if v.ApiSecret != "" {
+		secret = v.ApiSecret
+	}
+
+	return key, secret
+}
+
+func validVenue(name string) bool {
+	for _, v := range venueNames() {
+		if name == v {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (c *Config) validateVenues() error {
+	for name := range c.Venues {
+		if !validVenue(name) {
+			return fmt.Errorf("Invalid venue: %s", name)
+		}
+	}
+
+	return nil
+}
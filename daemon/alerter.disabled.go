@@ -2,4 +2,6 @@
 
 package daemon
 
-func (d *Daemon) initAlerter() {}
+import "context"
+
+func (d *Daemon) initAlerter(_ context.Context) {}
@@ -3,31 +3,202 @@
 package daemon
 
 import (
+	"context"
 	"time"
 
 	"github.com/stevenwilkin/treasury/alert"
+	"github.com/stevenwilkin/treasury/config"
+	"github.com/stevenwilkin/treasury/symbol"
 	"github.com/stevenwilkin/treasury/telegram"
 	"github.com/stevenwilkin/treasury/twilio"
 
 	log "github.com/sirupsen/logrus"
 )
 
-func (d *Daemon) initAlerter() {
+func (d *Daemon) addStanding(a config.Alert, standing alert.Alert) {
+	id := d.alerter.AddStandingAlert(standing)
+
+	if a.Repeat {
+		d.alerter.SetRepeat(id, &alert.Repeat{
+			Hysteresis: a.Hysteresis,
+			Cooldown:   a.Cooldown})
+	}
+}
+
+func (d *Daemon) addStandingAlert(a config.Alert) {
+	if a.Type == config.FeedAlert {
+		for _, f := range d.config.AlertFeeds(a) {
+			log.WithFields(log.Fields{
+				"type":   a.Type,
+				"feed":   f,
+				"window": a.Window,
+			}).Info("Adding standing alert")
+
+			d.addStanding(a, alert.NewFeedAlert(d.feedHandler, f, a.Window))
+		}
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"type":   a.Type,
+		"value":  a.Value,
+		"repeat": a.Repeat,
+	}).Info("Adding standing alert")
+
+	switch a.Type {
+	case config.FundingAlert:
+		d.addStanding(a, alert.NewFundingAlert(d.state))
+	case config.PriceAlert:
+		sym := symbol.BTCUSDT
+		if a.Symbol != "" {
+			sym, _ = symbol.FromString(a.Symbol)
+		}
+		direction, _ := alert.ParseDirection(a.Direction)
+		d.addStanding(a, alert.NewPriceAlert(d.state, sym, direction, a.Value))
+	case config.LeverageAlert:
+		d.addStanding(a, alert.NewLeverageAlert(d.state, a.Value))
+	case config.ExposureAlert:
+		d.addStanding(a, alert.NewExposureAlert(d.state, a.Value, a.Unit == "usd"))
+	}
+}
+
+func (d *Daemon) twilioNotifier(name string, tw *twilio.Twilio) alert.Notifier {
+	if len(tw.To) == 0 {
+		return d.outbox.Notifier(name, tw)
+	}
+
+	notifiers := alert.MultiNotifier{}
+	for _, to := range tw.To {
+		recipient := *tw
+		recipient.To = []string{to}
+		notifiers = append(notifiers, d.outbox.Notifier(name+" "+to, &recipient))
+	}
+
+	return notifiers
+}
+
+func (d *Daemon) escalationNotifier(s config.EscalationStep, tg *telegram.Telegram, tw *twilio.Twilio) alert.Notifier {
+	if s.Notifier == config.TelegramNotifier {
+		return d.outbox.Notifier("telegram", tg)
+	}
+
+	step := *tw
+	step.Sms = tw.Sms || s.Notifier == config.SmsNotifier
+
+	if s.To != "" {
+		step.To = []string{s.To}
+	}
+
+	return d.twilioNotifier(s.Notifier, &step)
+}
+
+func (d *Daemon) newTwilio() *twilio.Twilio {
+	tw := twilio.NewFromEnv()
+
+	if len(d.config.Twilio.To) > 0 {
+		tw.To = d.config.Twilio.To
+	}
+
+	tw.Sms = d.config.Twilio.Sms
+	tw.Voice = d.config.Twilio.Voice
+	tw.Loop = d.config.Twilio.Loop
+
+	return tw
+}
+
+func (d *Daemon) initEscalator(ctx context.Context, tg *telegram.Telegram, tw *twilio.Twilio) *alert.Escalator {
+	steps := []alert.Step{}
+	for _, s := range d.config.Escalation {
+		log.WithFields(log.Fields{
+			"notifier": s.Notifier,
+			"to":       s.To,
+			"after":    s.After,
+		}).Info("Adding escalation step")
+
+		steps = append(steps, alert.Step{
+			Notifier: d.escalationNotifier(s, tg, tw),
+			After:    s.After})
+	}
+
+	escalator := alert.NewEscalator(d.outbox.Notifier("telegram", tg), steps)
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		escalator.Run(ctx)
+	}()
+
+	return escalator
+}
+
+func (d *Daemon) initAlerter(ctx context.Context) {
 	log.Info("Initialising alerter")
 
-	notifier := alert.NewPriorityNotifier(
-		twilio.NewFromEnv(), telegram.NewFromEnv())
+	d.outbox = alert.NewOutbox(d.config.OutboxPath())
+	d.outbox.SetMaxAttempts(d.config.NotifyAttempts)
+	if err := d.outbox.Load(); err != nil {
+		log.WithField("path", d.config.OutboxPath()).Error(err.Error())
+	}
+
+	tg := telegram.NewFromEnv()
+	tw := d.newTwilio()
+
+	var notifier alert.Notifier
+	if len(d.config.Escalation) == 0 {
+		notifier = alert.NewPriorityNotifier(
+			d.twilioNotifier("twilio", tw), d.outbox.Notifier("telegram", tg))
+	} else {
+		notifier = d.initEscalator(ctx, tg, tw)
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.outbox.Run(ctx)
+	}()
 
 	d.alerter = alert.NewAlerter(d.state, notifier)
+
+	if tg.Configured() {
+		bot := telegram.NewBot(tg, d.state, d.alerter, d.feedHandler)
+		d.wg.Add(1)
+		go func() {
+			defer d.wg.Done()
+			bot.Run(ctx)
+		}()
+	} else {
+		log.Info("Telegram bot disabled")
+	}
+
 	d.alerter.Retrieve()
 
+	for _, a := range d.config.Alerts {
+		d.addStandingAlert(a)
+	}
+
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		d.alerter.Run(ctx)
+	}()
+
 	ticker := time.NewTicker(1 * time.Second)
+	d.wg.Add(1)
 	go func() {
+		defer d.wg.Done()
+		defer ticker.Stop()
+
 		for {
-			<-ticker.C
-			log.Debug("Checking alerts")
-			d.alerter.CheckAlerts()
-			d.alerter.Persist()
+			select {
+			case <-ticker.C:
+				log.Debug("Checking alerts")
+				d.alerter.CheckAlerts()
+				d.alerter.Persist()
+			case <-ctx.Done():
+				log.Info("Persisting alerts")
+				d.alerter.Persist()
+				return
+			}
 		}
 	}()
 }
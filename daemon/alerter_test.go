@@ -0,0 +1,87 @@
+//go:build !noalerter
+
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stevenwilkin/treasury/alert"
+	"github.com/stevenwilkin/treasury/config"
+	"github.com/stevenwilkin/treasury/feed"
+	"github.com/stevenwilkin/treasury/state"
+)
+
+func testDaemon(t *testing.T) *Daemon {
+	t.Setenv("TELEGRAM_CHAT_ID", "1")
+	t.Setenv("TWILIO_TO", "+440000, +440001")
+
+	c := config.Default()
+	c.DataDir = t.TempDir()
+
+	d := NewDaemon(c)
+	d.state = state.NewState()
+
+	return d
+}
+
+func TestNewTwilio(t *testing.T) {
+	d := testDaemon(t)
+	d.config.Twilio.Voice = "alice"
+	d.config.Twilio.Loop = 2
+
+	tw := d.newTwilio()
+	if len(tw.To) != 2 || tw.To[1] != "+440001" {
+		t.Errorf("Unexpected recipients %v", tw.To)
+	}
+
+	if tw.Voice != "alice" || tw.Loop != 2 {
+		t.Errorf("Unexpected voice %s, loop %d", tw.Voice, tw.Loop)
+	}
+
+	d.config.Twilio.To = []string{"+440002"}
+	if tw := d.newTwilio(); len(tw.To) != 1 || tw.To[0] != "+440002" {
+		t.Errorf("Should prefer configured recipients, got %v", tw.To)
+	}
+}
+
+func TestInitAlerter(t *testing.T) {
+	for _, escalation := range [][]config.EscalationStep{
+		nil,
+		{{Notifier: config.TelegramNotifier},
+			{Notifier: config.TwilioNotifier, After: time.Minute},
+			{Notifier: config.SmsNotifier, To: "+440002", After: time.Minute}}} {
+
+		d := testDaemon(t)
+		d.config.Escalation = escalation
+
+		ctx, cancel := context.WithCancel(context.Background())
+		d.feedHandler = feed.NewHandler(ctx)
+		d.initAlerter(ctx)
+
+		if d.alerter == nil || d.outbox == nil {
+			t.Error("Should initialise alerter and outbox")
+		}
+
+		cancel()
+		d.wg.Wait()
+	}
+}
+
+func TestTwilioDeliveryPerRecipient(t *testing.T) {
+	d := testDaemon(t)
+	d.outbox = alert.NewOutbox(d.config.OutboxPath())
+
+	tw := d.newTwilio()
+	d.twilioNotifier("twilio", tw).Notify(alert.NewFundingAlert(d.state))
+
+	deliveries := d.outbox.Deliveries()
+	if len(deliveries) != 2 {
+		t.Fatalf("Expected 2 deliveries, got %d", len(deliveries))
+	}
+
+	if deliveries[0].Notifier != "twilio +440000" || deliveries[1].Notifier != "twilio +440001" {
+		t.Errorf("Unexpected notifiers %s, %s", deliveries[0].Notifier, deliveries[1].Notifier)
+	}
+}
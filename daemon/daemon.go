@@ -1,6 +1,7 @@
 package daemon
 
 import (
+	"context"
 	"net"
 	"net/http"
 	"os"
@@ -8,8 +9,10 @@ import (
 	"time"
 
 	"github.com/stevenwilkin/treasury/alert"
+	"github.com/stevenwilkin/treasury/config"
 	"github.com/stevenwilkin/treasury/feed"
 	"github.com/stevenwilkin/treasury/handlers"
+	"github.com/stevenwilkin/treasury/history"
 	"github.com/stevenwilkin/treasury/state"
 	"github.com/stevenwilkin/treasury/venue"
 
@@ -18,39 +21,64 @@ import (
 )
 
 type Daemon struct {
-	state       *state.State
-	alerter     *alert.Alerter
-	feedHandler *feed.Handler
-	venues      venue.Venues
-	conns       map[*websocket.Conn]bool
-	m           sync.Mutex
+	config       *config.Config
+	state        *state.State
+	history      *history.Store
+	lastSnapshot time.Time
+	alerter      *alert.Alerter
+	outbox       *alert.Outbox
+	feedHandler  *feed.Handler
+	venues       venue.Registry
+	conns        map[*websocket.Conn]bool
+	m            sync.Mutex
+	wg           sync.WaitGroup
+	control      *http.Server
+	web          *http.Server
 }
 
 const (
-	socketPath = "/tmp/treasuryd.sock"
+	shutdownTimeout = 5 * time.Second
 )
 
-func (d *Daemon) initState() {
+func (d *Daemon) initState(ctx context.Context) {
 	log.Info("Initialising state")
+	if err := os.MkdirAll(d.config.DataDir, 0755); err != nil {
+		log.Fatal(err)
+	}
+
 	d.state = state.NewState()
-	d.state.Load()
+	d.state.SetPath(d.config.StatePath())
+	d.state.SetStaleAfter(d.config.StaleAfter)
+	if err := d.state.Load(); err != nil && !os.IsNotExist(err) {
+		log.Warn(err)
+	}
 
 	ticker := time.NewTicker(1 * time.Second)
+	d.wg.Add(1)
 	go func() {
+		defer d.wg.Done()
+		defer ticker.Stop()
+
 		for {
-			<-ticker.C
-			log.Debug("Persisting state")
-			d.state.Save()
+			select {
+			case t := <-ticker.C:
+				log.Debug("Persisting state")
+				d.state.Save()
+				d.recordSnapshot(t)
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
 }
 
 func (d *Daemon) initVenues() {
 	log.Info("Initialising venues")
-	d.venues = venue.NewVenues()
+	d.venues = venue.NewRegistry(d.config)
 }
 
 func (d *Daemon) initControlSocket() {
+	socketPath := d.config.SocketPath
 	log.Info("Initialising control socket ", socketPath)
 
 	if err := os.RemoveAll(socketPath); err != nil {
@@ -67,23 +95,55 @@ func (d *Daemon) initControlSocket() {
 		log.Fatal("chmod error:", err)
 	}
 
-	h := handlers.NewHandler(d.state, d.alerter, d.feedHandler, d.venues)
+	h := handlers.NewHandler(
+		d.state, d.alerter, d.outbox, d.feedHandler, d.venues, d.history)
+
+	d.control = &http.Server{Handler: h.Mux()}
 
 	go func() {
-		defer l.Close()
-		log.Fatal(http.Serve(l, h.Mux()))
+		if err := d.control.Serve(l); err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
 	}()
 }
 
-func (d *Daemon) Run() {
-	d.initState()
-	d.initAlerter()
+func (d *Daemon) shutdown() {
+	log.Info("Shutting down")
+
+	log.Info("Stopping data feeds")
+	d.feedHandler.Wait()
+	d.wg.Wait()
+
+	log.Info("Persisting state")
+	if err := d.state.Save(); err != nil {
+		log.Error(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer cancel()
+
+	log.Info("Closing web server")
+	d.web.Shutdown(ctx)
+	d.closeConns()
+
+	log.Info("Closing control socket")
+	d.control.Shutdown(ctx)
+	os.RemoveAll(d.config.SocketPath)
+}
+
+func (d *Daemon) Run(ctx context.Context) {
+	d.initHistory(ctx)
+	d.initState(ctx)
 	d.initVenues()
-	d.initDataFeeds()
+	d.initDataFeeds(ctx)
+	d.initAlerter(ctx)
 	d.initControlSocket()
-	d.initWS()
+	d.initWS(ctx)
+
+	<-ctx.Done()
+	d.shutdown()
 }
 
-func NewDaemon() *Daemon {
-	return &Daemon{}
+func NewDaemon(c *config.Config) *Daemon {
+	return &Daemon{config: c}
 }
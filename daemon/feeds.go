@@ -1,10 +1,10 @@
 package daemon
 
 import (
-	"reflect"
-	"time"
+	"context"
 
 	"github.com/stevenwilkin/treasury/asset"
+	"github.com/stevenwilkin/treasury/config"
 	"github.com/stevenwilkin/treasury/feed"
 	"github.com/stevenwilkin/treasury/symbol"
 	"github.com/stevenwilkin/treasury/venue"
@@ -12,109 +12,121 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
-func curry(f func(s symbol.Symbol) chan float64, s symbol.Symbol) func() chan float64 {
-	return func() chan float64 {
-		return f(s)
+func (d *Daemon) addPriceFeed(f config.Feed, sym symbol.Symbol, client venue.Client) bool {
+	sink := func(price float64) {
+		d.state.SetSymbolFrom(sym, price, f.Venue)
 	}
-}
-
-func poll(feed interface{}) interface{} {
-	returnType := reflect.ValueOf(feed).Type().Out(0)
-	chType := reflect.ChanOf(reflect.BothDir, returnType)
-
-	pollerF := func(_ []reflect.Value) []reflect.Value {
-		ch := reflect.MakeChan(chType, 0)
-		ticker := time.NewTicker(1 * time.Second)
-
-		go func() {
-			for {
-				results := reflect.ValueOf(feed).Call([]reflect.Value{})
-				if !results[1].IsNil() {
-					ch.Close()
-					return
-				}
 
-				ch.Send(results[0])
-				<-ticker.C
-			}
-		}()
-
-		return []reflect.Value{ch}
+	switch {
+	case client.PriceStreamer != nil:
+		source := func(ctx context.Context, report func(error)) chan float64 {
+			return client.PriceStreamer.Price(ctx, sym, report)
+		}
+		feed.Add(d.feedHandler, f.Feed, source, sink)
+	case client.PriceProvider != nil:
+		fn := func() (float64, error) {
+			return client.PriceProvider.GetPrice(sym)
+		}
+		feed.Add(d.feedHandler, f.Feed, feed.Poll(fn, f.Interval), sink)
+	default:
+		return false
 	}
 
-	fnType := reflect.FuncOf([]reflect.Type{}, []reflect.Type{chType}, false)
-	fn := reflect.MakeFunc(fnType, pollerF)
-
-	return fn.Interface()
+	return true
 }
 
-func (d *Daemon) initDataFeeds() {
-	log.Info("Initialising data feeds")
-	d.feedHandler = feed.NewHandler()
+func (d *Daemon) addBalanceFeed(f config.Feed, v venue.Venue, client venue.Client) bool {
+	sink := func(balances asset.Balances) {
+		for a, q := range balances {
+			d.state.SetAssetFrom(v, a, q, f.Venue)
+		}
+	}
 
-	d.feedHandler.Add(
-		feed.BTCUSDT,
-		d.venues.Binance.Price,
-		func(btcUsdt float64) {
-			d.state.SetSymbol(symbol.BTCUSDT, btcUsdt)
+	switch {
+	case client.BalanceStreamer != nil:
+		feed.Add(d.feedHandler, f.Feed, client.BalanceStreamer.Balances, sink)
+	case client.BalanceProvider != nil:
+		feed.Add(d.feedHandler, f.Feed,
+			feed.Poll(client.BalanceProvider.GetBalances, f.Interval), sink)
+	case client.AccountProvider != nil:
+		type account struct {
+			balances asset.Balances
+			leverage float64
+		}
+
+		fn := func() (account, error) {
+			balances, leverage, err := client.AccountProvider.GetAccount()
+			return account{balances, leverage}, err
+		}
+
+		feed.Add(d.feedHandler, f.Feed, feed.Poll(fn, f.Interval), func(a account) {
+			sink(a.balances)
+			d.state.SetLeverage(v, a.leverage)
 		})
+	default:
+		return false
+	}
 
-	d.feedHandler.Add(
-		feed.Binance,
-		poll(d.venues.Binance.GetBalances),
-		func(balances [3]float64) {
-			d.state.SetAsset(venue.Binance, asset.BTC, balances[0])
-			d.state.SetAsset(venue.Binance, asset.USDT, balances[1])
-			d.state.SetAsset(venue.Binance, asset.USDC, balances[2])
-		})
+	return true
+}
 
-	d.feedHandler.Add(
-		feed.BTCTHB,
-		curry(d.venues.Bitkub.PriceWS, symbol.BTCTHB),
-		func(btcThb float64) {
-			d.state.SetSymbol(symbol.BTCTHB, btcThb)
-		})
+func (d *Daemon) addFeed(f config.Feed) {
+	v, err := venue.FromString(f.Venue)
+	if err != nil {
+		log.WithField("feed", f.Feed).Error(err)
+		return
+	}
 
-	d.feedHandler.Add(
-		feed.USDTTHB,
-		curry(d.venues.Bitkub.PriceWS, symbol.USDTTHB),
-		func(usdtThb float64) {
-			d.state.SetSymbol(symbol.USDTTHB, usdtThb)
-		})
+	client := d.venues[v]
+	added := false
+
+	if sym, err := symbol.FromString(f.Feed.String()); err == nil {
+		added = d.addPriceFeed(f, sym, client)
+	} else {
+		switch f.Feed {
+		case feed.Funding:
+			if client.FundingProvider != nil {
+				feed.Add(
+					d.feedHandler,
+					f.Feed,
+					feed.Poll(client.FundingProvider.GetFundingRate, f.Interval),
+					func(funding float64) {
+						d.state.SetFundingRate(funding)
+					})
+				added = true
+			}
 
-	d.feedHandler.Add(
-		feed.USDTHB,
-		poll(d.venues.XE.GetPrice),
-		func(usdThb float64) {
-			d.state.SetSymbol(symbol.USDTHB, usdThb)
-		})
+		case feed.LeverageDeribit:
+			if client.PositionProvider != nil {
+				feed.Add(
+					d.feedHandler,
+					f.Feed,
+					feed.Poll(client.PositionProvider.GetLeverage, f.Interval),
+					func(leverage float64) {
+						d.state.SetLeverage(v, leverage)
+					})
+				added = true
+			}
 
-	d.feedHandler.Add(
-		feed.Deribit,
-		d.venues.Deribit.Equity,
-		func(deribitBtc float64) {
-			d.state.SetAsset(venue.Deribit, asset.BTC, deribitBtc)
-		})
+		default:
+			added = d.addBalanceFeed(f, v, client)
+		}
+	}
 
-	d.feedHandler.Add(
-		feed.Bybit,
-		poll(d.venues.Bybit.GetEquityAndLeverage),
-		func(equityAndLeverage [2]float64) {
-			d.state.SetAsset(venue.Bybit, asset.BTC, equityAndLeverage[0])
-			d.state.SetLeverageBybit(equityAndLeverage[1])
-		})
+	if !added {
+		log.WithFields(log.Fields{
+			"feed":  f.Feed,
+			"venue": f.Venue,
+		}).Error("Venue does not provide feed")
+	}
+}
 
-	d.feedHandler.Add(
-		feed.Funding,
-		poll(d.venues.Bybit.GetFundingRate),
-		func(funding float64) {
-			d.state.SetFundingRate(funding)
-		})
+func (d *Daemon) initDataFeeds(ctx context.Context) {
+	log.Info("Initialising data feeds")
+	d.feedHandler = feed.NewHandler(ctx)
+	d.feedHandler.SetReactivateAfter(d.config.ReactivateAfter)
 
-	d.feedHandler.Add(
-		feed.LeverageDeribit,
-		poll(d.venues.Deribit.GetLeverage),
-		func(leverage float64) {
-			d.state.SetLeverageDeribit(leverage)
-		})
+	for _, f := range d.config.EnabledFeeds() {
+		d.addFeed(f)
+	}
 }
@@ -0,0 +1,74 @@
+package daemon
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stevenwilkin/treasury/asset"
+	"github.com/stevenwilkin/treasury/config"
+	"github.com/stevenwilkin/treasury/feed"
+	"github.com/stevenwilkin/treasury/state"
+	"github.com/stevenwilkin/treasury/venue"
+
+	"github.com/sirupsen/logrus/hooks/test"
+)
+
+type testAccount struct {
+	calls int
+}
+
+func (a *testAccount) GetAccount() (asset.Balances, float64, error) {
+	a.calls += 1
+	return asset.Balances{asset.BTC: 2}, 3, nil
+}
+
+func feedDaemon(t *testing.T, r venue.Registry) *Daemon {
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	return &Daemon{
+		state:       state.NewState(),
+		venues:      r,
+		feedHandler: feed.NewHandler(ctx)}
+}
+
+func TestAddAccountFeed(t *testing.T) {
+	account := &testAccount{}
+	d := feedDaemon(t, venue.Registry{venue.Bybit: {AccountProvider: account}})
+
+	d.addFeed(config.Feed{Feed: feed.Bybit, Venue: config.Bybit, Interval: time.Hour})
+
+	for i := 0; i < 100 && d.state.GetLeverageBybit() == 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	if balance := d.state.GetAsset(venue.Bybit, asset.BTC); balance != 2 {
+		t.Errorf("Expected: '%f', got: '%f'", 2.0, balance)
+	}
+
+	if leverage := d.state.GetLeverageBybit(); leverage != 3 {
+		t.Errorf("Expected: '%f', got: '%f'", 3.0, leverage)
+	}
+
+	if account.calls != 1 {
+		t.Errorf("Should fetch the account once per poll, got %d calls", account.calls)
+	}
+}
+
+func TestAddFeedWithoutProvider(t *testing.T) {
+	hook := test.NewGlobal()
+	defer hook.Reset()
+
+	d := feedDaemon(t, venue.Registry{venue.Bybit: {}})
+
+	d.addFeed(config.Feed{Feed: feed.Funding, Venue: config.Bybit, Interval: time.Hour})
+
+	if len(d.feedHandler.Status()) != 0 {
+		t.Error("Should not add feed")
+	}
+
+	if entry := hook.LastEntry(); entry == nil || entry.Message != "Venue does not provide feed" {
+		t.Errorf("Should log missing feed, got %v", entry)
+	}
+}
@@ -0,0 +1,60 @@
+package daemon
+
+import (
+	"context"
+	"time"
+
+	"github.com/stevenwilkin/treasury/history"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	snapshotInterval = 1 * time.Minute
+	compactInterval  = 24 * time.Hour
+)
+
+func (d *Daemon) initHistory(ctx context.Context) {
+	historyPath := d.config.HistoryPath()
+	log.Info("Initialising history ", historyPath)
+	d.history = history.NewStore(historyPath)
+
+	ticker := time.NewTicker(compactInterval)
+	d.wg.Add(1)
+	go func() {
+		defer d.wg.Done()
+		defer ticker.Stop()
+
+		for {
+			log.Debug("Compacting history")
+			if err := d.history.Compact(time.Now()); err != nil {
+				log.Warn(err)
+			}
+
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+}
+
+func (d *Daemon) recordSnapshot(t time.Time) {
+	if t.Sub(d.lastSnapshot) < snapshotInterval {
+		return
+	}
+
+	snap := history.NewSnapshot(d.state, t)
+	if len(snap.Symbols) == 0 {
+		return
+	}
+
+	log.Debug("Recording snapshot")
+	if err := d.history.Append(snap); err != nil {
+		log.Warn(err)
+		return
+	}
+
+	d.lastSnapshot = t
+}
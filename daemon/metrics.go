@@ -0,0 +1,18 @@
+package daemon
+
+import (
+	"net/http"
+
+	"github.com/stevenwilkin/treasury/metrics"
+
+	log "github.com/sirupsen/logrus"
+)
+
+func (d *Daemon) serveMetrics(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", metrics.ContentType)
+
+	gauges := metrics.Collect(d.state, d.feedHandler, d.alerter)
+	if err := metrics.Write(w, gauges); err != nil {
+		log.Debug(err)
+	}
+}
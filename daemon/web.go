@@ -1,26 +1,61 @@
 package daemon
 
 import (
+	"context"
 	"fmt"
 	"net/http"
-	"os"
 	"time"
 
 	"github.com/gorilla/websocket"
 	log "github.com/sirupsen/logrus"
 )
 
-func (d *Daemon) initWS() {
+func (d *Daemon) connections() []*websocket.Conn {
+	d.m.Lock()
+	defer d.m.Unlock()
+
+	conns := make([]*websocket.Conn, 0, len(d.conns))
+	for c := range d.conns {
+		conns = append(conns, c)
+	}
+
+	return conns
+}
+
+func (d *Daemon) closeConns() {
+	message := websocket.FormatCloseMessage(
+		websocket.CloseGoingAway, "shutting down")
+	deadline := time.Now().Add(time.Second)
+
+	for _, c := range d.connections() {
+		c.WriteControl(websocket.CloseMessage, message, deadline)
+		c.Close()
+	}
+
+	d.m.Lock()
+	d.conns = map[*websocket.Conn]bool{}
+	d.m.Unlock()
+}
+
+func (d *Daemon) initWS(ctx context.Context) {
 	d.conns = map[*websocket.Conn]bool{}
 
-	http.HandleFunc("/ws", d.serveWs)
+	mux := http.NewServeMux()
+	mux.HandleFunc("/ws", d.serveWs)
+	mux.HandleFunc("/metrics", d.serveMetrics)
 
 	ticker := time.NewTicker(1 * time.Second)
 
+	d.wg.Add(1)
 	go func() {
+		defer d.wg.Done()
+		defer ticker.Stop()
+
 		for {
-			for c, _ := range d.conns {
-				if err := d.sendState(c); err != nil {
+			sm := newStateMessage(d.state.Snapshot())
+
+			for _, c := range d.connections() {
+				if err := d.sendState(c, sm); err != nil {
 					log.Debug(err)
 					d.m.Lock()
 					delete(d.conns, c)
@@ -28,17 +63,22 @@ func (d *Daemon) initWS() {
 				}
 			}
 
-			<-ticker.C
+			select {
+			case <-ticker.C:
+			case <-ctx.Done():
+				return
+			}
 		}
 	}()
 
-	port := "8080"
-	if wwwPort := os.Getenv("WWW_PORT"); len(wwwPort) > 0 {
-		port = wwwPort
-	}
+	d.web = &http.Server{
+		Addr:    fmt.Sprintf(":%s", d.config.WWWPort),
+		Handler: mux}
 
 	go func() {
-		log.Infof("Listening on 0.0.0.0:%s", port)
-		log.Fatal(http.ListenAndServe(fmt.Sprintf(":%s", port), nil))
+		log.Infof("Listening on 0.0.0.0:%s", d.config.WWWPort)
+		if err := d.web.ListenAndServe(); err != http.ErrServerClosed {
+			log.Fatal(err)
+		}
 	}()
 }
@@ -4,6 +4,7 @@ import (
 	"net/http"
 	"os"
 
+	"github.com/stevenwilkin/treasury/state"
 	"github.com/stevenwilkin/treasury/symbol"
 
 	"github.com/gorilla/websocket"
@@ -20,6 +21,7 @@ type stateMessage struct {
 	PnlPercentage   float64                       `json:"pnl_percentage"`
 	LeverageDeribit float64                       `json:"leverage_deribit"`
 	LeverageBybit   float64                       `json:"leverage_bybit"`
+	Warnings        []string                      `json:"warnings"`
 }
 
 type authMessage struct {
@@ -30,33 +32,45 @@ type authResponseMessage struct {
 	Error string `json:"error"`
 }
 
-func (d *Daemon) sendState(c *websocket.Conn) error {
-	log.Debug("Sending state")
-
-	usdThb := d.state.Symbol(symbol.USDTHB)
-
+func newStateMessage(snap *state.Snapshot) stateMessage {
 	sm := stateMessage{
 		Assets:          map[string]map[string]float64{},
 		Prices:          map[string]float64{},
-		Exposure:        d.state.Exposure(),
-		Cost:            d.state.Cost / usdThb,
-		Value:           d.state.TotalValue() / usdThb,
-		Pnl:             d.state.Pnl() / usdThb,
-		PnlPercentage:   d.state.PnlPercentage(),
-		LeverageDeribit: d.state.GetLeverageDeribit(),
-		LeverageBybit:   d.state.GetLeverageBybit()}
-
-	for v, balances := range d.state.GetAssets() {
+		PnlPercentage:   snap.PnlPercentage(),
+		LeverageDeribit: snap.GetLeverageDeribit(),
+		LeverageBybit:   snap.GetLeverageBybit(),
+		Warnings: append(
+			snap.ValueWarnings(), snap.ExposureWarnings()...)}
+
+	if snap.Symbol(symbol.BTCUSDT) > 0 {
+		sm.Exposure = snap.Exposure()
+	}
+
+	if usdThb := snap.Symbol(symbol.USDTHB); usdThb > 0 {
+		sm.Cost = snap.GetCost() / usdThb
+		sm.Value = snap.TotalValue() / usdThb
+		sm.Pnl = snap.Pnl() / usdThb
+	} else {
+		sm.Warnings = append(sm.Warnings, snap.SymbolWarnings(symbol.USDTHB)...)
+	}
+
+	for v, balances := range snap.GetAssets() {
 		sm.Assets[v.String()] = map[string]float64{}
 		for a, q := range balances {
 			sm.Assets[v.String()][a.String()] = q
 		}
 	}
 
-	for s, p := range d.state.GetSymbols() {
+	for s, p := range snap.GetSymbols() {
 		sm.Prices[s.String()] = p
 	}
 
+	return sm
+}
+
+func (d *Daemon) sendState(c *websocket.Conn, sm stateMessage) error {
+	log.Debug("Sending state")
+
 	if err := c.WriteJSON(sm); err != nil {
 		return err
 	}
@@ -95,7 +109,7 @@ func (d *Daemon) serveWs(w http.ResponseWriter, r *http.Request) {
 		c.WriteJSON(authResponseMessage{})
 	}
 
-	d.sendState(c)
+	d.sendState(c, newStateMessage(d.state.Snapshot()))
 
 	d.m.Lock()
 	d.conns[c] = true
@@ -1,6 +1,7 @@
 package deribit
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io/ioutil"
@@ -9,6 +10,8 @@ import (
 	"net/url"
 	"time"
 
+	"github.com/stevenwilkin/treasury/asset"
+
 	"github.com/gorilla/websocket"
 	_ "github.com/joho/godotenv/autoload"
 	log "github.com/sirupsen/logrus"
@@ -108,6 +111,8 @@ func (d *Deribit) subscribe(channels []string) (*websocket.Conn, error) {
 	testMessage := requestMessage{Method: "/public/test"}
 
 	go func() {
+		defer ticker.Stop()
+
 		for {
 			if err = c.WriteJSON(testMessage); err != nil {
 				log.WithField("venue", "deribit").Debug("Heartbeat stopping")
@@ -120,20 +125,35 @@ func (d *Deribit) subscribe(channels []string) (*websocket.Conn, error) {
 	return c, nil
 }
 
-func (d *Deribit) Equity() chan float64 {
-	ch := make(chan float64)
+func (d *Deribit) Balances(ctx context.Context, report func(error)) chan asset.Balances {
+	ch := make(chan asset.Balances)
 	c, err := d.subscribe([]string{"user.portfolio.BTC"})
 	if err != nil {
 		log.WithField("venue", "deribit").Warn(err.Error())
+		report(err)
 		close(ch)
 		return ch
 	}
 
+	done := make(chan struct{})
 	go func() {
+		select {
+		case <-ctx.Done():
+			c.Close()
+		case <-done:
+		}
+	}()
+
+	go func() {
+		defer close(done)
+
 		for {
 			_, message, err := c.ReadMessage()
 			if err != nil {
-				log.WithField("venue", "deribit").Warn(err.Error())
+				if ctx.Err() == nil {
+					log.WithField("venue", "deribit").Warn(err.Error())
+					report(err)
+				}
 				c.Close()
 				close(ch)
 				return
@@ -151,7 +171,7 @@ func (d *Deribit) Equity() chan float64 {
 				"asset": "BTC",
 				"value": response.Params.Data.Equity,
 			}).Debug("Received equity")
-			ch <- response.Params.Data.Equity
+			ch <- asset.Balances{asset.BTC: response.Params.Data.Equity}
 		}
 	}()
 
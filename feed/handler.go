@@ -1,36 +1,80 @@
 package feed
 
 import (
+	"context"
 	"math"
-	"reflect"
 	"sync"
 	"time"
 
 	log "github.com/sirupsen/logrus"
 )
 
-var (
-	delayBase  = 2.0
-	maxRetries = 6
+const (
+	defaultDelayBase  = 2.0
+	defaultRateWindow = time.Minute
 )
 
+var maxRetries = 6
+
 type Handler struct {
-	feeds Status
-	m     sync.Mutex
+	ctx             context.Context
+	feeds           Status
+	reactivateAfter time.Duration
+	delayBase       float64
+	rateWindow      time.Duration
+	m               sync.Mutex
+	wg              sync.WaitGroup
 }
 
+type Source[T any] func(context.Context, func(error)) chan T
+
+type Sink[T any] func(T)
+
 type FeedStatus struct {
-	inputF     interface{}
-	outputF    interface{}
+	start      func() func() bool
+	wake       chan struct{}
+	coolingOff bool
+	started    bool
+	reported   bool
+	updates    []time.Time
 	Active     bool
 	LastUpdate time.Time
 	Errors     int
+	Restarts   int
+	LastError  string
+	Messages   int
+	Rate       float64
+	Backoff    time.Duration
+	NextRetry  time.Time
+}
+
+func (s FeedStatus) Failed() bool {
+	return s.Errors > maxRetries
 }
+
+func (s *FeedStatus) rate(now time.Time, window time.Duration) float64 {
+	for len(s.updates) > 0 && now.Sub(s.updates[0]) > window {
+		s.updates = s.updates[1:]
+	}
+
+	return float64(len(s.updates)) / window.Seconds()
+}
+
 type Status map[Feed]*FeedStatus
 
-func NewHandler() *Handler {
+func NewHandler(ctx context.Context) *Handler {
 	return &Handler{
-		feeds: Status{}}
+		ctx:        ctx,
+		feeds:      Status{},
+		delayBase:  defaultDelayBase,
+		rateWindow: defaultRateWindow}
+}
+
+func (h *Handler) SetReactivateAfter(d time.Duration) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.reactivateAfter = d
 }
 
 func (h *Handler) feedStatus(f Feed) FeedStatus {
@@ -40,33 +84,68 @@ func (h *Handler) feedStatus(f Feed) FeedStatus {
 	return *h.feeds[f]
 }
 
-func (h *Handler) startFeed(f Feed) reflect.Value {
+func (h *Handler) startFeed(f Feed) func() bool {
 	log.WithField("feed", f).Info("Starting feed")
-	fn := h.feedStatus(f).inputF
-	return reflect.ValueOf(fn).Call([]reflect.Value{})[0]
+
+	h.m.Lock()
+	status := h.feeds[f]
+	if status.started {
+		status.Restarts += 1
+	}
+	status.started = true
+	status.reported = false
+	status.Backoff = 0
+	status.NextRetry = time.Time{}
+	h.m.Unlock()
+
+	return status.start()
+}
+
+func (h *Handler) setError(f Feed, err error) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.feeds[f].LastError = err.Error()
+	h.feeds[f].reported = true
 }
 
 func (h *Handler) setFailed(f Feed) {
 	h.m.Lock()
 	h.feeds[f].Active = false
 	h.feeds[f].Errors += 1
+	if !h.feeds[f].reported {
+		h.feeds[f].LastError = "Feed closed"
+	}
 	h.m.Unlock()
 }
 
-func (h *Handler) processFeed(f Feed, item reflect.Value) {
+func (h *Handler) setRetry(f Feed, delay time.Duration) {
+	h.m.Lock()
+	defer h.m.Unlock()
+
+	h.feeds[f].Backoff = delay
+	h.feeds[f].NextRetry = time.Now().Add(delay)
+}
+
+func (h *Handler) processFeed(f Feed, sink func()) {
 	h.m.Lock()
 	defer h.m.Unlock()
 
-	h.feeds[f].LastUpdate = time.Now()
-	h.feeds[f].Active = true
-	h.feeds[f].Errors = 0
+	status := h.feeds[f]
+	now := time.Now()
 
-	fn := h.feeds[f].outputF
-	reflect.ValueOf(fn).Call([]reflect.Value{item})
+	status.updates = append(status.updates, now)
+	status.Rate = status.rate(now, h.rateWindow)
+	status.LastUpdate = now
+	status.Active = true
+	status.Errors = 0
+	status.Messages += 1
+
+	sink()
 }
 
 func (h *Handler) canRestart(f Feed) bool {
-	return h.feedStatus(f).Errors <= maxRetries
+	return !h.feedStatus(f).Failed()
 }
 
 func (h *Handler) canReactivate(f Feed) bool {
@@ -81,63 +160,158 @@ func (h *Handler) canReactivate(f Feed) bool {
 	}
 }
 
-func (h *Handler) exponentialBackoff(f Feed) {
-	delaySeconds := math.Pow(delayBase, float64(h.feedStatus(f).Errors))
+func (h *Handler) exponentialBackoff(f Feed) bool {
+	delaySeconds := math.Pow(h.delayBase, float64(h.feedStatus(f).Errors))
 	delay := time.Second * time.Duration(delaySeconds)
 	log.WithFields(log.Fields{
 		"feed":  f,
 		"delay": delay,
 	}).Warn("Backing off feed")
-	time.Sleep(delay)
+	h.setRetry(f, delay)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	select {
+	case <-timer.C:
+		return true
+	case <-h.ctx.Done():
+		return false
+	}
+}
+
+func (h *Handler) coolOff(f Feed) bool {
+	h.m.Lock()
+	delay := h.reactivateAfter
+	status := h.feeds[f]
+	status.coolingOff = delay > 0
+	h.m.Unlock()
+
+	if delay <= 0 {
+		return false
+	}
+
+	log.WithFields(log.Fields{
+		"feed":  f,
+		"delay": delay,
+	}).Warn("Cooling off feed")
+	h.setRetry(f, delay)
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+
+	defer func() {
+		h.m.Lock()
+		status.coolingOff = false
+		status.Errors = 0
+		h.m.Unlock()
+	}()
+
+	select {
+	case <-timer.C:
+	case <-status.wake:
+	case <-h.ctx.Done():
+		return false
+	}
+
+	log.WithField("feed", f).Info("Reactivating feed")
+	return true
 }
 
 func (h *Handler) handle(f Feed) {
+	h.wg.Add(1)
 	go func() {
-		ch := h.startFeed(f)
+		defer h.wg.Done()
+
+		next := h.startFeed(f)
 		for {
-			item, ok := ch.Recv()
-			if !ok {
+			if !next() {
+				if h.ctx.Err() != nil {
+					log.WithField("feed", f).Info("Stopped feed")
+					return
+				}
+
 				h.setFailed(f)
 				if h.canRestart(f) {
-					h.exponentialBackoff(f)
-					ch = h.startFeed(f)
+					if !h.exponentialBackoff(f) {
+						return
+					}
+					next = h.startFeed(f)
 				} else {
 					log.WithField("feed", f).Error("Feed failed")
-					return
+					if !h.coolOff(f) {
+						return
+					}
+					next = h.startFeed(f)
 				}
-			} else {
-				h.processFeed(f, item)
 			}
 		}
 	}()
 }
 
-func (h *Handler) Add(f Feed, inputF interface{}, outputF interface{}) {
+func (h *Handler) add(f Feed, start func() func() bool) {
 	h.m.Lock()
 	defer h.m.Unlock()
 
 	h.feeds[f] = &FeedStatus{
-		inputF:  inputF,
-		outputF: outputF,
-		Active:  true}
+		start:  start,
+		wake:   make(chan struct{}, 1),
+		Active: true}
 
 	h.handle(f)
 }
 
+func Add[T any](h *Handler, f Feed, source Source[T], sink Sink[T]) {
+	report := func(err error) { h.setError(f, err) }
+
+	h.add(f, func() func() bool {
+		ch := source(h.ctx, report)
+
+		return func() bool {
+			item, ok := <-ch
+			if ok {
+				h.processFeed(f, func() { sink(item) })
+			}
+
+			return ok
+		}
+	})
+}
+
+func (h *Handler) Wait() {
+	h.wg.Wait()
+}
+
 func (h *Handler) Reactivate(f Feed) {
-	if h.canReactivate(f) {
-		log.WithField("feed", f).Info("Reactivating feed")
-		h.handle(f)
+	if !h.canReactivate(f) {
+		return
 	}
+
+	status := h.feedStatus(f)
+	if status.coolingOff {
+		select {
+		case status.wake <- struct{}{}:
+		default:
+		}
+		return
+	}
+
+	log.WithField("feed", f).Info("Reactivating feed")
+	h.handle(f)
 }
 
 func (h *Handler) Status() map[Feed]FeedStatus {
 	h.m.Lock()
 	defer h.m.Unlock()
 
+	now := time.Now()
 	result := map[Feed]FeedStatus{}
 	for f, s := range h.feeds {
-		result[f] = *s
+		s.Rate = s.rate(now, h.rateWindow)
+
+		status := *s
+		status.updates = nil
+		result[f] = status
 	}
 
 	return result
@@ -1,13 +1,28 @@
 package feed
 
 import (
+	"context"
+	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 )
 
+func waitFor(t *testing.T, message string, condition func() bool) {
+	t.Helper()
+
+	deadline := time.Now().Add(time.Second)
+	for !condition() {
+		if time.Now().After(deadline) {
+			t.Fatal(message)
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
 func TestLastUpdate(t *testing.T) {
 	trigger := make(chan bool)
-	f := func() chan int {
+	f := func(context.Context, func(error)) chan int {
 		ch := make(chan int)
 		go func() {
 			<-trigger
@@ -16,8 +31,8 @@ func TestLastUpdate(t *testing.T) {
 		return ch
 	}
 
-	h := NewHandler()
-	h.Add(BTCUSDT, f, func(int) {})
+	h := NewHandler(context.Background())
+	Add(h, BTCUSDT, f, func(int) {})
 
 	if h.Status()[BTCUSDT].LastUpdate != (time.Time{}) {
 		t.Error("Should not have a last update")
@@ -25,14 +40,14 @@ func TestLastUpdate(t *testing.T) {
 
 	trigger <- true
 
-	if h.Status()[BTCUSDT].LastUpdate == (time.Time{}) {
-		t.Error("Should have a last update")
-	}
+	waitFor(t, "Should have a last update", func() bool {
+		return h.Status()[BTCUSDT].LastUpdate != (time.Time{})
+	})
 }
 
 func TestUpdateClearsErrorCountAndSetsActive(t *testing.T) {
 	trigger := make(chan bool)
-	f := func() chan int {
+	f := func(context.Context, func(error)) chan int {
 		ch := make(chan int)
 		go func() {
 			<-trigger
@@ -41,24 +56,21 @@ func TestUpdateClearsErrorCountAndSetsActive(t *testing.T) {
 		return ch
 	}
 
-	h := NewHandler()
-	h.Add(BTCUSDT, f, func(int) {})
+	h := NewHandler(context.Background())
+	Add(h, BTCUSDT, f, func(int) {})
 	h.setFailed(BTCUSDT)
 
 	trigger <- true
 
-	if h.Status()[BTCUSDT].Errors != 0 {
-		t.Error("Should have 0 errors")
-	}
-
-	if !h.Status()[BTCUSDT].Active {
-		t.Error("Should be active")
-	}
+	waitFor(t, "Should have 0 errors and be active", func() bool {
+		status := h.Status()[BTCUSDT]
+		return status.Errors == 0 && status.Active
+	})
 }
 
 func TestClosingChannel(t *testing.T) {
 	trigger := make(chan bool)
-	f := func() chan int {
+	f := func(context.Context, func(error)) chan int {
 		ch := make(chan int)
 		go func() {
 			<-trigger
@@ -68,8 +80,8 @@ func TestClosingChannel(t *testing.T) {
 		return ch
 	}
 
-	h := NewHandler()
-	h.Add(BTCUSDT, f, func(int) {})
+	h := NewHandler(context.Background())
+	Add(h, BTCUSDT, f, func(int) {})
 
 	if !h.Status()[BTCUSDT].Active {
 		t.Error("Should be active")
@@ -77,42 +89,66 @@ func TestClosingChannel(t *testing.T) {
 
 	trigger <- true
 
-	if h.Status()[BTCUSDT].Active {
-		t.Error("Should not be active")
-	}
+	waitFor(t, "Should not be active", func() bool {
+		return !h.Status()[BTCUSDT].Active
+	})
 
 	if h.Status()[BTCUSDT].Errors != 1 {
 		t.Error("Should have 1 error")
 	}
 }
 
-func TestClosingChannelRestart(t *testing.T) {
-	delayBase = 0
+func TestFailed(t *testing.T) {
+	f := func(context.Context, func(error)) chan int {
+		return make(chan int)
+	}
+
+	h := NewHandler(context.Background())
+	Add(h, BTCUSDT, f, func(int) {})
+	h.setFailed(BTCUSDT)
 
-	count := 0
-	f := func() chan int {
-		count += 1
+	if h.Status()[BTCUSDT].Failed() {
+		t.Error("Should not fail after a single error")
+	}
+
+	for i := 0; i < maxRetries; i++ {
+		h.setFailed(BTCUSDT)
+	}
+
+	if !h.Status()[BTCUSDT].Failed() {
+		t.Error("Should fail after exceeding max retries")
+	}
+}
+
+func TestClosingChannelRestart(t *testing.T) {
+	var count atomic.Int32
+	f := func(context.Context, func(error)) chan int {
+		count.Add(1)
 		ch := make(chan int)
 		close(ch)
 		return ch
 	}
 
-	h := NewHandler()
-	h.Add(BTCUSDT, f, func(int) {})
+	h := NewHandler(context.Background())
+	h.delayBase = 0
+	Add(h, BTCUSDT, f, func(int) {})
 
-	time.Sleep(time.Millisecond) // nasty
+	waitFor(t, "Feed should have failed", func() bool {
+		return h.Status()[BTCUSDT].Failed()
+	})
+	h.Wait()
 
-	if count < 2 {
+	if count.Load() < 2 {
 		t.Error("Feed should have been restarted after failure")
 	}
 
-	if count > maxRetries+1 {
+	if count.Load() > int32(maxRetries+1) {
 		t.Error("Feed should not exceed max retries")
 	}
 }
 
 func TestCanReactivateNonAddedFeed(t *testing.T) {
-	h := NewHandler()
+	h := NewHandler(context.Background())
 
 	if h.canReactivate(USDTHB) {
 		t.Error("Should not be able to reactivate non-added feed")
@@ -120,49 +156,52 @@ func TestCanReactivateNonAddedFeed(t *testing.T) {
 }
 
 func TestCanReactivate(t *testing.T) {
-	delayBase = 0
-
-	sendValue := true
-	f := func() chan int {
+	var fail atomic.Bool
+	trigger := make(chan bool)
+	f := func(context.Context, func(error)) chan int {
 		ch := make(chan int)
 		go func() {
-			if sendValue {
+			if !fail.Load() {
 				ch <- 1
-			} else {
-				close(ch)
+				<-trigger
 			}
+			close(ch)
 		}()
 		return ch
 	}
 
-	h := NewHandler()
-	h.Add(BTCUSDT, f, func(int) {})
+	h := NewHandler(context.Background())
+	h.delayBase = 0
+	Add(h, BTCUSDT, f, func(int) {})
+
+	waitFor(t, "Should receive a message", func() bool {
+		return h.Status()[BTCUSDT].Messages == 1
+	})
 
 	if h.canReactivate(BTCUSDT) {
 		t.Error("Should not be able to reactivate")
 	}
 
-	sendValue = false
-	time.Sleep(time.Millisecond)
+	fail.Store(true)
+	trigger <- true
 
-	if !h.canReactivate(BTCUSDT) {
-		t.Error("Should be able to reactivate")
-	}
+	waitFor(t, "Should be able to reactivate", func() bool {
+		return h.canReactivate(BTCUSDT)
+	})
 }
 
 func TestReactivateUnaddedFeed(t *testing.T) {
-	h := NewHandler()
+	h := NewHandler(context.Background())
 	h.Reactivate(USDTHB)
 }
 
 func TestReactivateFeed(t *testing.T) {
-	delayBase = 0
-
-	closeCh := true
-	f := func() chan int {
+	var closeCh atomic.Bool
+	closeCh.Store(true)
+	f := func(context.Context, func(error)) chan int {
 		ch := make(chan int)
 		go func() {
-			if closeCh {
+			if closeCh.Load() {
 				close(ch)
 			} else {
 				ch <- 1
@@ -171,21 +210,232 @@ func TestReactivateFeed(t *testing.T) {
 		return ch
 	}
 
-	h := NewHandler()
-	h.Add(BTCUSDT, f, func(int) {})
+	h := NewHandler(context.Background())
+	h.delayBase = 0
+	Add(h, BTCUSDT, f, func(int) {})
 
-	time.Sleep(time.Millisecond)
+	waitFor(t, "Should be able to reactivate", func() bool {
+		return h.canReactivate(BTCUSDT)
+	})
 
-	if !h.canReactivate(BTCUSDT) {
-		t.Error("Should be able to reactivate")
+	closeCh.Store(false)
+
+	h.Reactivate(BTCUSDT)
+
+	waitFor(t, "Should not be able to reactivate", func() bool {
+		return !h.canReactivate(BTCUSDT)
+	})
+}
+
+func TestCancellingContextStopsFeeds(t *testing.T) {
+	f := func(ctx context.Context, _ func(error)) chan int {
+		ch := make(chan int)
+		go func() {
+			<-ctx.Done()
+			close(ch)
+		}()
+		return ch
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := NewHandler(ctx)
+	Add(h, BTCUSDT, f, func(int) {})
+
+	cancel()
+	h.Wait()
+
+	if h.Status()[BTCUSDT].Errors != 0 {
+		t.Error("Stopping a feed should not count as an error")
 	}
+}
 
-	closeCh = false
+func TestReactivateAfterCoolOff(t *testing.T) {
+	var count atomic.Int32
+	f := func(context.Context, func(error)) chan int {
+		count.Add(1)
+		ch := make(chan int)
+		close(ch)
+		return ch
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := NewHandler(ctx)
+	h.delayBase = 0
+	h.SetReactivateAfter(time.Millisecond)
+	Add(h, BTCUSDT, f, func(int) {})
+
+	waitFor(t, "Feed should have been reactivated after cooling off", func() bool {
+		return count.Load() > int32(maxRetries+1)
+	})
+
+	cancel()
+	h.Wait()
+}
+
+func TestReactivateWhileCoolingOff(t *testing.T) {
+	var count atomic.Int32
+	f := func(context.Context, func(error)) chan int {
+		count.Add(1)
+		ch := make(chan int)
+		close(ch)
+		return ch
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := NewHandler(ctx)
+	h.delayBase = 0
+	h.SetReactivateAfter(time.Hour)
+	Add(h, BTCUSDT, f, func(int) {})
+
+	waitFor(t, "Feed should be cooling off", func() bool {
+		return h.Status()[BTCUSDT].coolingOff
+	})
+
+	if count.Load() != int32(maxRetries+1) {
+		t.Errorf("Feed should be cooling off after %d attempts, got %d",
+			maxRetries+1, count.Load())
+	}
 
 	h.Reactivate(BTCUSDT)
-	time.Sleep(time.Millisecond)
 
-	if h.canReactivate(BTCUSDT) {
-		t.Error("Should not be able to reactivate")
+	waitFor(t, "Feed should be cooling off again", func() bool {
+		return count.Load() == int32(2*(maxRetries+1)) &&
+			h.Status()[BTCUSDT].coolingOff
+	})
+
+	cancel()
+	h.Wait()
+
+	if count.Load() != int32(2*(maxRetries+1)) {
+		t.Errorf("Feed should have been reactivated once, got %d attempts", count.Load())
+	}
+}
+
+func TestMessagesAndRate(t *testing.T) {
+	f := func(context.Context, func(error)) chan int {
+		ch := make(chan int)
+		go func() {
+			for i := 0; i < 3; i++ {
+				ch <- i
+				time.Sleep(10 * time.Millisecond)
+			}
+		}()
+		return ch
+	}
+
+	h := NewHandler(context.Background())
+	Add(h, BTCUSDT, f, func(int) {})
+
+	waitFor(t, "Should have received 3 messages", func() bool {
+		return h.Status()[BTCUSDT].Messages == 3
+	})
+
+	if rate := h.Status()[BTCUSDT].Rate; rate <= 0 || rate > 100 {
+		t.Errorf("Unexpected rate %f", rate)
+	}
+}
+
+func TestRateDecays(t *testing.T) {
+	f := func(context.Context, func(error)) chan int {
+		ch := make(chan int)
+		go func() {
+			ch <- 1
+		}()
+		return ch
+	}
+
+	h := NewHandler(context.Background())
+	h.rateWindow = 100 * time.Millisecond
+	Add(h, BTCUSDT, f, func(int) {})
+
+	waitFor(t, "Should have a rate", func() bool {
+		return h.Status()[BTCUSDT].Rate > 0
+	})
+
+	waitFor(t, "Rate should decay to 0", func() bool {
+		return h.Status()[BTCUSDT].Rate == 0
+	})
+}
+
+func TestReportedErrorAndRestarts(t *testing.T) {
+	f := func(_ context.Context, report func(error)) chan int {
+		ch := make(chan int)
+		go func() {
+			report(errors.New("Invalid API key"))
+			close(ch)
+		}()
+		return ch
+	}
+
+	h := NewHandler(context.Background())
+	h.delayBase = 0
+	Add(h, BTCUSDT, f, func(int) {})
+
+	waitFor(t, "Feed should have failed", func() bool {
+		return h.Status()[BTCUSDT].Failed()
+	})
+	h.Wait()
+
+	status := h.Status()[BTCUSDT]
+
+	if status.LastError != "Invalid API key" {
+		t.Errorf("Unexpected last error '%s'", status.LastError)
+	}
+
+	if status.Restarts != maxRetries {
+		t.Errorf("Should have restarted %d times, got %d",
+			maxRetries, status.Restarts)
+	}
+}
+
+func TestUnreportedError(t *testing.T) {
+	f := func(context.Context, func(error)) chan int {
+		ch := make(chan int)
+		close(ch)
+		return ch
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := NewHandler(ctx)
+	Add(h, BTCUSDT, f, func(int) {})
+
+	waitFor(t, "Should have an error", func() bool {
+		return h.Status()[BTCUSDT].Errors > 0
+	})
+
+	status := h.Status()[BTCUSDT]
+	cancel()
+	h.Wait()
+
+	if status.LastError != "Feed closed" {
+		t.Errorf("Unexpected last error '%s'", status.LastError)
+	}
+}
+
+func TestBackoffAndNextRetry(t *testing.T) {
+	f := func(context.Context, func(error)) chan int {
+		ch := make(chan int)
+		close(ch)
+		return ch
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	h := NewHandler(ctx)
+	Add(h, BTCUSDT, f, func(int) {})
+
+	waitFor(t, "Should back off", func() bool {
+		return h.Status()[BTCUSDT].Backoff > 0
+	})
+
+	status := h.Status()[BTCUSDT]
+	cancel()
+	h.Wait()
+
+	if status.Backoff != 2*time.Second {
+		t.Errorf("Unexpected backoff %s", status.Backoff)
+	}
+
+	if status.NextRetry.Before(time.Now()) {
+		t.Error("Next retry should be in the future")
 	}
 }
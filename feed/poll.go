@@ -0,0 +1,41 @@
+package feed
+
+import (
+	"context"
+	"time"
+)
+
+func Poll[T any](fn func() (T, error), interval time.Duration) Source[T] {
+	return func(ctx context.Context, report func(error)) chan T {
+		ch := make(chan T)
+
+		go func() {
+			defer close(ch)
+
+			ticker := time.NewTicker(interval)
+			defer ticker.Stop()
+
+			for {
+				item, err := fn()
+				if err != nil {
+					report(err)
+					return
+				}
+
+				select {
+				case ch <- item:
+				case <-ctx.Done():
+					return
+				}
+
+				select {
+				case <-ticker.C:
+				case <-ctx.Done():
+					return
+				}
+			}
+		}()
+
+		return ch
+	}
+}
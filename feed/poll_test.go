@@ -0,0 +1,65 @@
+package feed
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestPoll(t *testing.T) {
+	count := 0
+	fn := func() (int, error) {
+		count += 1
+		return count, nil
+	}
+
+	ch := Poll(fn, time.Millisecond)(context.Background(), func(error) {})
+
+	if <-ch != 1 || <-ch != 2 {
+		t.Error("Should send successive results")
+	}
+}
+
+func TestPollClosesChannelOnError(t *testing.T) {
+	fn := func() (int, error) {
+		return 0, errors.New("Fail")
+	}
+
+	ch := Poll(fn, time.Millisecond)(context.Background(), func(error) {})
+
+	if _, ok := <-ch; ok {
+		t.Error("Channel should be closed")
+	}
+}
+
+func TestPollStopsOnCancel(t *testing.T) {
+	fn := func() (int, error) {
+		return 1, nil
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	ch := Poll(fn, time.Hour)(ctx, func(error) {})
+
+	<-ch
+	cancel()
+
+	if _, ok := <-ch; ok {
+		t.Error("Channel should be closed")
+	}
+}
+
+func TestPollReportsError(t *testing.T) {
+	fn := func() (int, error) {
+		return 0, errors.New("Fail")
+	}
+
+	var reported error
+	report := func(err error) { reported = err }
+
+	<-Poll(fn, time.Millisecond)(context.Background(), report)
+
+	if reported == nil || reported.Error() != "Fail" {
+		t.Errorf("Should report error, got %v", reported)
+	}
+}
@@ -1,10 +1,16 @@
 module github.com/stevenwilkin/treasury
 
-go 1.14
+go 1.21
 
 require (
 	github.com/gorilla/websocket v1.4.2
 	github.com/joho/godotenv v1.3.0
 	github.com/sirupsen/logrus v1.9.4
 	github.com/spf13/cobra v1.0.0
+	gopkg.in/yaml.v3 v3.0.1
+)
+
+require (
+	github.com/spf13/pflag v1.0.3 // indirect
+	golang.org/x/sys v0.13.0 // indirect
 )
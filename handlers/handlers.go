@@ -2,12 +2,17 @@ package handlers
 
 import (
 	"encoding/json"
+	"errors"
+	"math"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/stevenwilkin/treasury/alert"
 	"github.com/stevenwilkin/treasury/asset"
 	"github.com/stevenwilkin/treasury/feed"
+	"github.com/stevenwilkin/treasury/history"
 	"github.com/stevenwilkin/treasury/state"
 	"github.com/stevenwilkin/treasury/symbol"
 	"github.com/stevenwilkin/treasury/venue"
@@ -16,18 +21,22 @@ import (
 )
 
 type Handler struct {
-	s *state.State
-	a *alert.Alerter
-	f *feed.Handler
-	v venue.Venues
+	s  *state.State
+	a  *alert.Alerter
+	o  *alert.Outbox
+	f  *feed.Handler
+	v  venue.Registry
+	hs *history.Store
 }
 
-func NewHandler(s *state.State, a *alert.Alerter, f *feed.Handler, v venue.Venues) *Handler {
+func NewHandler(s *state.State, a *alert.Alerter, o *alert.Outbox, f *feed.Handler, v venue.Registry, hs *history.Store) *Handler {
 	return &Handler{
-		a: a,
-		s: s,
-		f: f,
-		v: v}
+		a:  a,
+		o:  o,
+		s:  s,
+		f:  f,
+		v:  v,
+		hs: hs}
 }
 
 func (h *Handler) Prices(w http.ResponseWriter, r *http.Request) {
@@ -104,11 +113,14 @@ func (h *Handler) SetCost(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) PnL(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	snap := h.s.Snapshot()
+
 	pm := pnlMessage{
-		Cost:          h.s.Cost,
-		Value:         h.s.TotalValue(),
-		Pnl:           h.s.Pnl(),
-		PnlPercentage: h.s.PnlPercentage(),
+		Cost:          snap.GetCost(),
+		Value:         snap.TotalValue(),
+		Pnl:           snap.Pnl(),
+		PnlPercentage: snap.PnlPercentage(),
+		Warnings:      snap.ValueWarnings(),
 	}
 
 	b, err := json.Marshal(pm)
@@ -120,15 +132,23 @@ func (h *Handler) PnL(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) PnLUSD(w http.ResponseWriter, r *http.Request) {
-	w.Header().Set("Content-Type", "application/json")
+	snap := h.s.Snapshot()
 
-	usdThb := h.s.Symbol(symbol.USDTHB)
+	usdThb := snap.Symbol(symbol.USDTHB)
+	if usdThb == 0 {
+		http.Error(w, "USDTHB missing", http.StatusServiceUnavailable)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
 
 	pm := pnlMessage{
-		Cost:          h.s.Cost / usdThb,
-		Value:         h.s.TotalValue() / usdThb,
-		Pnl:           h.s.Pnl() / usdThb,
-		PnlPercentage: h.s.PnlPercentage(),
+		Cost:          snap.GetCost() / usdThb,
+		Value:         snap.TotalValue() / usdThb,
+		Pnl:           snap.Pnl() / usdThb,
+		PnlPercentage: snap.PnlPercentage(),
+		Warnings: append(
+			snap.ValueWarnings(), snap.SymbolWarnings(symbol.USDTHB)...),
 	}
 
 	b, err := json.Marshal(pm)
@@ -142,13 +162,30 @@ func (h *Handler) PnLUSD(w http.ResponseWriter, r *http.Request) {
 func (h *Handler) Alerts(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
-	alerts := h.a.Alerts()
+	alerts := h.a.List()
 	am := make([]alertMessage, len(alerts))
+	now := time.Now()
 
-	for i, alert := range alerts {
+	for i, info := range alerts {
 		am[i] = alertMessage{
-			Active:      alert.Active(),
-			Description: alert.Description()}
+			Id:          info.Id,
+			Active:      info.Active,
+			Description: info.Description,
+			Created:     info.Created,
+			Value:       info.Value,
+			Standing:    info.Standing,
+			Repeat:      info.Repeat != nil,
+			Escalating:  info.Escalating}
+
+		if !info.Triggered.IsZero() {
+			triggered := info.Triggered
+			am[i].Triggered = &triggered
+		}
+
+		if info.Snoozed.After(now) {
+			snoozed := info.Snoozed
+			am[i].Snoozed = &snoozed
+		}
 	}
 
 	b, err := json.Marshal(am)
@@ -164,16 +201,269 @@ func (h *Handler) ClearAlerts(w http.ResponseWriter, r *http.Request) {
 	h.a.ClearAlerts()
 }
 
+func parseRepeat(r *http.Request) (*alert.Repeat, error) {
+	if r.FormValue("repeat") == "" {
+		return nil, nil
+	}
+
+	repeat, err := strconv.ParseBool(r.FormValue("repeat"))
+	if err != nil || !repeat {
+		return nil, err
+	}
+
+	var result alert.Repeat
+
+	if r.FormValue("hysteresis") != "" {
+		result.Hysteresis, err = strconv.ParseFloat(r.FormValue("hysteresis"), 64)
+		if err != nil || result.Hysteresis < 0 {
+			return nil, errors.New("Invalid hysteresis")
+		}
+	}
+
+	if r.FormValue("cooldown") != "" {
+		result.Cooldown, err = time.ParseDuration(r.FormValue("cooldown"))
+		if err != nil || result.Cooldown < 0 {
+			return nil, errors.New("Invalid cooldown")
+		}
+	}
+
+	return &result, nil
+}
+
+func (h *Handler) alertCreated(w http.ResponseWriter, id int, repeat *alert.Repeat) {
+	if repeat != nil {
+		log.WithFields(log.Fields{
+			"id":         id,
+			"hysteresis": repeat.Hysteresis,
+			"cooldown":   repeat.Cooldown,
+		}).Info("Setting alert to repeat")
+
+		h.a.SetRepeat(id, repeat)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	b, err := json.Marshal(alertIdMessage{Id: id})
+	if err != nil {
+		log.Error(err)
+	}
+
+	w.Write(b)
+}
+
+func writeAlertError(w http.ResponseWriter, err error) {
+	if errors.Is(err, alert.ErrNotFound) {
+		http.Error(w, err.Error(), http.StatusNotFound)
+		return
+	}
+
+	if errors.Is(err, alert.ErrNotEscalating) {
+		http.Error(w, err.Error(), http.StatusConflict)
+		return
+	}
+
+	http.Error(w, err.Error(), http.StatusInternalServerError)
+}
+
+func (h *Handler) DeleteAlert(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	log.WithField("id", id).Info("Deleting alert")
+
+	if err = h.a.Delete(id); err != nil {
+		writeAlertError(w, err)
+	}
+}
+
+func (h *Handler) SnoozeAlert(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	d, err := time.ParseDuration(r.FormValue("duration"))
+	if err != nil || d <= 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	log.WithField("id", id).Infof("Snoozing alert - %s", d)
+
+	if err = h.a.Snooze(id, d); err != nil {
+		writeAlertError(w, err)
+	}
+}
+
+func (h *Handler) RearmAlert(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	log.WithField("id", id).Info("Re-arming alert")
+
+	if err = h.a.Rearm(id); err != nil {
+		writeAlertError(w, err)
+	}
+}
+
+func (h *Handler) AckAlert(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	log.WithField("id", id).Info("Acknowledging alert")
+
+	if err = h.a.Ack(id); err != nil {
+		writeAlertError(w, err)
+	}
+}
+
+func (h *Handler) Notifications(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+
+	nm := []notificationMessage{}
+
+	if h.o != nil {
+		for _, d := range h.o.Deliveries() {
+			nm = append(nm, notificationMessage{
+				Id:          d.Id,
+				Notifier:    d.Notifier,
+				AlertId:     d.AlertId,
+				Description: d.Description,
+				Created:     d.Created,
+				Attempts:    d.Attempts,
+				NextAttempt: d.NextAttempt,
+				LastError:   d.LastError,
+				Failed:      d.Failed})
+		}
+	}
+
+	b, err := json.Marshal(nm)
+	if err != nil {
+		log.Error(err)
+	}
+
+	w.Write(b)
+}
+
+func (h *Handler) RetryNotification(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.Atoi(r.FormValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	if h.o == nil {
+		http.Error(w, alert.ErrDeliveryNotFound.Error(), http.StatusNotFound)
+		return
+	}
+
+	log.WithField("id", id).Info("Retrying notification")
+
+	if err = h.o.Retry(id); err == alert.ErrDeliveryNotFound {
+		http.Error(w, err.Error(), http.StatusNotFound)
+	} else if err != nil {
+		http.Error(w, err.Error(), http.StatusConflict)
+	}
+}
+
 func (h *Handler) AddPriceAlert(w http.ResponseWriter, r *http.Request) {
+	repeat, err := parseRepeat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	v, err := strconv.ParseFloat(r.FormValue("value"), 64)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 
-	log.Infof("Setting price alert - %f", v)
+	sym := symbol.BTCUSDT
+	if r.FormValue("symbol") != "" {
+		if sym, err = symbol.FromString(r.FormValue("symbol")); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	d := alert.Crosses
+	if r.FormValue("direction") != "" {
+		if d, err = alert.ParseDirection(r.FormValue("direction")); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	var expires time.Time
+	if r.FormValue("expires") != "" {
+		expires, err = time.Parse(time.RFC3339, r.FormValue("expires"))
+		if err != nil || expires.Before(time.Now()) {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"symbol":    sym,
+		"direction": d,
+		"expires":   expires,
+		"note":      r.FormValue("note"),
+	}).Infof("Setting price alert - %f", v)
+
+	h.alertCreated(w, h.a.AddPriceAlert(sym, d, v, expires, r.FormValue("note")), repeat)
+}
+
+func (h *Handler) AddMoveAlert(w http.ResponseWriter, r *http.Request) {
+	repeat, err := parseRepeat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	sym, err := symbol.FromString(r.FormValue("symbol"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	percentage, err := strconv.ParseFloat(r.FormValue("percentage"), 64)
+	if err != nil || percentage <= 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
 
-	h.a.AddPriceAlert(v)
+	window, err := time.ParseDuration(r.FormValue("window"))
+	if err != nil || window <= 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	m := alert.Either
+	if r.FormValue("direction") != "" {
+		if m, err = alert.ParseMove(r.FormValue("direction")); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	log.WithFields(log.Fields{
+		"symbol":    sym,
+		"direction": m,
+		"window":    window,
+	}).Infof("Setting move alert - %f%%", percentage)
+
+	h.alertCreated(w, h.a.AddMoveAlert(sym, m, percentage, window), repeat)
 }
 
 func (h *Handler) Funding(w http.ResponseWriter, r *http.Request) {
@@ -192,20 +482,28 @@ func (h *Handler) Funding(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) AddFundingAlert(w http.ResponseWriter, r *http.Request) {
+	repeat, err := parseRepeat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	log.Infof("Setting funding alert")
 
-	h.a.AddFundingAlert()
+	h.alertCreated(w, h.a.AddFundingAlert(), repeat)
 }
 
 func (h *Handler) Leverage(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
+	snap := h.s.Snapshot()
+
 	fm := struct {
 		Deribit float64 `json:"deribit"`
 		Bybit   float64 `json:"bybit"`
 	}{
-		Deribit: h.s.GetLeverageDeribit(),
-		Bybit:   h.s.GetLeverageBybit()}
+		Deribit: snap.GetLeverageDeribit(),
+		Bybit:   snap.GetLeverageBybit()}
 
 	b, err := json.Marshal(fm)
 	if err != nil {
@@ -216,6 +514,12 @@ func (h *Handler) Leverage(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) AddLeverageAlert(w http.ResponseWriter, r *http.Request) {
+	repeat, err := parseRepeat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
 	v, err := strconv.ParseFloat(r.FormValue("value"), 64)
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
@@ -224,16 +528,53 @@ func (h *Handler) AddLeverageAlert(w http.ResponseWriter, r *http.Request) {
 
 	log.Infof("Setting leverage alert - %f", v)
 
-	h.a.AddLeverageAlert(v)
+	h.alertCreated(w, h.a.AddLeverageAlert(v), repeat)
+}
+
+func (h *Handler) AddExposureAlert(w http.ResponseWriter, r *http.Request) {
+	repeat, err := parseRepeat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	v, err := strconv.ParseFloat(r.FormValue("value"), 64)
+	if err != nil || v <= 0 {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	usd := false
+	switch strings.ToLower(r.FormValue("unit")) {
+	case "", "btc":
+	case "usd":
+		usd = true
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	log.WithField("usd", usd).Infof("Setting exposure alert - %f", v)
+
+	h.alertCreated(w, h.a.AddExposureAlert(v, usd), repeat)
 }
 
 func (h *Handler) Exposure(w http.ResponseWriter, r *http.Request) {
+	snap := h.s.Snapshot()
+
+	if snap.Symbol(symbol.BTCUSDT) == 0 {
+		http.Error(w, "BTCUSDT missing", http.StatusServiceUnavailable)
+		return
+	}
+
 	w.Header().Set("Content-Type", "application/json")
 
 	fm := struct {
-		Value float64 `json:"value"`
+		Value    float64  `json:"value"`
+		Warnings []string `json:"warnings,omitempty"`
 	}{
-		Value: h.s.Exposure()}
+		Value:    snap.Exposure(),
+		Warnings: snap.ExposureWarnings()}
 
 	b, err := json.Marshal(fm)
 	if err != nil {
@@ -260,7 +601,12 @@ func (h *Handler) Size(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) UpdateSize(w http.ResponseWriter, r *http.Request) {
-	size := h.v.Deribit.GetSize() + h.v.Bybit.GetSize()
+	var size int
+
+	for _, pp := range h.v.PositionProviders() {
+		size += pp.GetSize()
+	}
+
 	log.Infof("Setting size to %d", size)
 
 	h.s.SetSize(size)
@@ -275,7 +621,15 @@ func (h *Handler) Feeds(w http.ResponseWriter, r *http.Request) {
 
 	for feed, status := range h.f.Status() {
 		fr.Feeds[feed.String()] = feedsResponseItem{
-			Active: status.Active, LastUpdate: status.LastUpdate}
+			Active:     status.Active,
+			LastUpdate: status.LastUpdate,
+			Errors:     status.Errors,
+			Restarts:   status.Restarts,
+			LastError:  status.LastError,
+			Messages:   status.Messages,
+			Rate:       status.Rate,
+			Backoff:    status.Backoff,
+			NextRetry:  status.NextRetry}
 	}
 
 	b, err := json.Marshal(fr)
@@ -297,9 +651,18 @@ func (h *Handler) ReactivateFeed(w http.ResponseWriter, r *http.Request) {
 }
 
 func (h *Handler) Indicators(w http.ResponseWriter, r *http.Request) {
-	data := map[string]float64{
-		"thb_premium":  h.s.THBPremium(),
-		"usdt_premium": h.s.USDTPremium()}
+	w.Header().Set("Content-Type", "application/json")
+
+	snap := h.s.Snapshot()
+
+	data := struct {
+		THBPremium  float64  `json:"thb_premium"`
+		USDTPremium float64  `json:"usdt_premium"`
+		Warnings    []string `json:"warnings,omitempty"`
+	}{
+		THBPremium:  snap.THBPremium(),
+		USDTPremium: snap.USDTPremium(),
+		Warnings:    snap.PremiumWarnings()}
 
 	b, err := json.Marshal(data)
 	if err != nil {
@@ -309,6 +672,92 @@ func (h *Handler) Indicators(w http.ResponseWriter, r *http.Request) {
 	w.Write(b)
 }
 
+func (h *Handler) AddPremiumAlert(w http.ResponseWriter, r *http.Request) {
+	repeat, err := parseRepeat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	i, err := alert.ParseIndicator(r.FormValue("indicator"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	bounds := []float64{math.Inf(-1), math.Inf(1)}
+	for j, name := range []string{"lower", "upper"} {
+		if r.FormValue(name) == "" {
+			continue
+		}
+
+		if bounds[j], err = strconv.ParseFloat(r.FormValue(name), 64); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	lower, upper := bounds[0], bounds[1]
+	if (math.IsInf(lower, -1) && math.IsInf(upper, 1)) || lower >= upper {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"indicator": i,
+		"lower":     lower,
+		"upper":     upper,
+	}).Info("Setting premium alert")
+
+	h.alertCreated(w, h.a.AddPremiumAlert(i, lower, upper), repeat)
+}
+
+func (h *Handler) AddPortfolioAlert(w http.ResponseWriter, r *http.Request) {
+	repeat, err := parseRepeat(r)
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
+		return
+	}
+
+	m, err := alert.ParsePortfolioMetric(r.FormValue("metric"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	v, err := strconv.ParseFloat(r.FormValue("value"), 64)
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	d := alert.Crosses
+	if r.FormValue("direction") != "" {
+		if d, err = alert.ParseDirection(r.FormValue("direction")); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	usd := false
+	switch strings.ToLower(r.FormValue("currency")) {
+	case "", "thb":
+	case "usd":
+		usd = true
+	default:
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	log.WithFields(log.Fields{
+		"metric":    m,
+		"direction": d,
+		"usd":       usd,
+	}).Infof("Setting portfolio alert - %f", v)
+
+	h.alertCreated(w, h.a.AddPortfolioAlert(m, usd, d, v), repeat)
+}
+
 func (h *Handler) Loan(w http.ResponseWriter, r *http.Request) {
 	w.Header().Set("Content-Type", "application/json")
 
@@ -337,6 +786,62 @@ func (h *Handler) SetLoan(w http.ResponseWriter, r *http.Request) {
 	h.s.SetLoan(l)
 }
 
+func parseTime(value string, fallback time.Time) (time.Time, error) {
+	if value == "" {
+		return fallback, nil
+	}
+
+	return time.Parse(time.RFC3339, value)
+}
+
+func (h *Handler) History(w http.ResponseWriter, r *http.Request) {
+	m, err := history.ParseMetric(r.FormValue("metric"))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	until, err := parseTime(r.FormValue("until"), time.Now())
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	since, err := parseTime(r.FormValue("since"), until.Add(-24*time.Hour))
+	if err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+
+	var step time.Duration
+	if stepValue := r.FormValue("step"); stepValue != "" {
+		if step, err = time.ParseDuration(stepValue); err != nil || step < 0 {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+	}
+
+	snaps, err := h.hs.Range(since, until)
+	if err != nil {
+		log.Error(err)
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+
+	hm := historyMessage{
+		Metric: r.FormValue("metric"),
+		Points: history.Series(snaps, m, since, step)}
+
+	b, err := json.Marshal(hm)
+	if err != nil {
+		log.Error(err)
+	}
+
+	w.Write(b)
+}
+
 func (h *Handler) Mux() *http.ServeMux {
 	mux := http.NewServeMux()
 	mux.HandleFunc("/prices", h.Prices)
@@ -347,9 +852,19 @@ func (h *Handler) Mux() *http.ServeMux {
 	mux.HandleFunc("/pnl/usd", h.PnLUSD)
 	mux.HandleFunc("/alerts", h.Alerts)
 	mux.HandleFunc("/alerts/clear", h.ClearAlerts)
+	mux.HandleFunc("/alerts/delete", h.DeleteAlert)
+	mux.HandleFunc("/alerts/snooze", h.SnoozeAlert)
+	mux.HandleFunc("/alerts/rearm", h.RearmAlert)
+	mux.HandleFunc("/alerts/ack", h.AckAlert)
 	mux.HandleFunc("/alerts/price", h.AddPriceAlert)
+	mux.HandleFunc("/alerts/move", h.AddMoveAlert)
+	mux.HandleFunc("/alerts/exposure", h.AddExposureAlert)
+	mux.HandleFunc("/alerts/premium", h.AddPremiumAlert)
+	mux.HandleFunc("/alerts/portfolio", h.AddPortfolioAlert)
 	mux.HandleFunc("/alerts/funding", h.AddFundingAlert)
 	mux.HandleFunc("/alerts/leverage", h.AddLeverageAlert)
+	mux.HandleFunc("/notifications", h.Notifications)
+	mux.HandleFunc("/notifications/retry", h.RetryNotification)
 	mux.HandleFunc("/funding", h.Funding)
 	mux.HandleFunc("/exposure", h.Exposure)
 	mux.HandleFunc("/leverage", h.Leverage)
@@ -360,6 +875,7 @@ func (h *Handler) Mux() *http.ServeMux {
 	mux.HandleFunc("/indicators", h.Indicators)
 	mux.HandleFunc("/loan", h.Loan)
 	mux.HandleFunc("/loan/set", h.SetLoan)
+	mux.HandleFunc("/history", h.History)
 
 	return mux
 }
@@ -1,15 +1,21 @@
 package handlers
 
 import (
+	"context"
+	"encoding/json"
 	"net/http"
 	"net/http/httptest"
 	"net/url"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/stevenwilkin/treasury/alert"
 	"github.com/stevenwilkin/treasury/asset"
 	"github.com/stevenwilkin/treasury/feed"
+	"github.com/stevenwilkin/treasury/history"
 	"github.com/stevenwilkin/treasury/state"
 	"github.com/stevenwilkin/treasury/venue"
 )
@@ -22,10 +28,23 @@ var (
 	s = state.NewState()
 	h = NewHandler(s,
 		alert.NewAlerter(s, &TestNotifier{}),
-		feed.NewHandler(),
-		venue.Venues{})
+		alert.NewOutbox(""),
+		feed.NewHandler(context.Background()),
+		venue.Registry{},
+		history.NewStore(""))
 )
 
+func testHandler(t *testing.T) *Handler {
+	dir := t.TempDir()
+
+	return NewHandler(s,
+		alert.NewAlerter(s, &TestNotifier{}),
+		alert.NewOutbox(filepath.Join(dir, "outbox.json")),
+		feed.NewHandler(context.Background()),
+		venue.Registry{},
+		history.NewStore(filepath.Join(dir, "history")))
+}
+
 func TestSetAssetInvalidVenue(t *testing.T) {
 	params := url.Values{}
 	params.Set("venue", "fake")
@@ -131,15 +150,236 @@ func TestAddPriceAlert(t *testing.T) {
 	handler := http.HandlerFunc(h.AddPriceAlert)
 	handler.ServeHTTP(w, r)
 
-	if len(h.a.Alerts()) != 1 {
+	if len(h.a.List()) != 1 {
 		t.Error("Should set an alert")
 	}
 
-	alert := h.a.Alerts()[0]
+	if strings.TrimSpace(w.Body.String()) != `{"id":1}` {
+		t.Errorf("Expected alert id, got: '%s'", w.Body.String())
+	}
+
+	alert := h.a.List()[0]
 	expected := "Price alert at BTCUSDT 20000.00"
 
-	if alert.Description() != expected {
-		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Description())
+	if alert.Description != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Description)
+	}
+}
+
+func TestAddPriceAlertWithSymbolAndDirection(t *testing.T) {
+	h.a = alert.NewAlerter(s, &TestNotifier{})
+
+	params := url.Values{
+		"symbol":    {"usdthb"},
+		"direction": {"below"},
+		"value":     {"30"},
+		"note":      {"buy"}}
+	body := strings.NewReader(params.Encode())
+
+	r, err := http.NewRequest("POST", "/alerts/price", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.AddPriceAlert)
+	handler.ServeHTTP(w, r)
+
+	if len(h.a.List()) != 1 {
+		t.Fatal("Should set an alert")
+	}
+
+	alert := h.a.List()[0]
+	expected := "Price alert at USDTHB below 30.00 - buy"
+
+	if alert.Description != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Description)
+	}
+}
+
+func TestAddPriceAlertInvalidDirection(t *testing.T) {
+	h.a = alert.NewAlerter(s, &TestNotifier{})
+
+	params := url.Values{"direction": {"sideways"}, "value": {"30"}}
+	body := strings.NewReader(params.Encode())
+
+	r, err := http.NewRequest("POST", "/alerts/price", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.AddPriceAlert)
+	handler.ServeHTTP(w, r)
+
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("Unexpected status code %d", w.Result().StatusCode)
+	}
+
+	if len(h.a.List()) != 0 {
+		t.Error("Should not set an alert")
+	}
+}
+
+func TestAddMoveAlert(t *testing.T) {
+	h.a = alert.NewAlerter(s, &TestNotifier{})
+
+	params := url.Values{
+		"symbol":     {"BTCUSDT"},
+		"percentage": {"5"},
+		"window":     {"1h"},
+		"direction":  {"down"}}
+	body := strings.NewReader(params.Encode())
+
+	r, err := http.NewRequest("POST", "/alerts/move", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.AddMoveAlert)
+	handler.ServeHTTP(w, r)
+
+	if len(h.a.List()) != 1 {
+		t.Fatal("Should set an alert")
+	}
+
+	alert := h.a.List()[0]
+	expected := "Move alert on BTCUSDT 5.00% down within 1h0m0s"
+
+	if alert.Description != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Description)
+	}
+}
+
+func TestAddMoveAlertInvalidWindow(t *testing.T) {
+	h.a = alert.NewAlerter(s, &TestNotifier{})
+
+	params := url.Values{
+		"symbol": {"BTCUSDT"}, "percentage": {"5"}, "window": {"soon"}}
+	body := strings.NewReader(params.Encode())
+
+	r, err := http.NewRequest("POST", "/alerts/move", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.AddMoveAlert)
+	handler.ServeHTTP(w, r)
+
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("Unexpected status code %d", w.Result().StatusCode)
+	}
+}
+
+func TestAddExposureAlert(t *testing.T) {
+	h.a = alert.NewAlerter(s, &TestNotifier{})
+
+	params := url.Values{"value": {"10000"}, "unit": {"usd"}}
+	body := strings.NewReader(params.Encode())
+
+	r, err := http.NewRequest("POST", "/alerts/exposure", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.AddExposureAlert)
+	handler.ServeHTTP(w, r)
+
+	if len(h.a.List()) != 1 {
+		t.Fatal("Should set an alert")
+	}
+
+	alert := h.a.List()[0]
+	expected := "Exposure alert at 10000.00 USD"
+
+	if alert.Description != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Description)
+	}
+}
+
+func TestAddPremiumAlert(t *testing.T) {
+	h.a = alert.NewAlerter(s, &TestNotifier{})
+
+	params := url.Values{"indicator": {"thb"}, "upper": {"3"}}
+	body := strings.NewReader(params.Encode())
+
+	r, err := http.NewRequest("POST", "/alerts/premium", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.AddPremiumAlert)
+	handler.ServeHTTP(w, r)
+
+	if len(h.a.List()) != 1 {
+		t.Fatal("Should set an alert")
+	}
+
+	alert := h.a.List()[0]
+	expected := "Premium alert on thb above +3.00%"
+
+	if alert.Description != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Description)
+	}
+}
+
+func TestAddPremiumAlertWithoutBounds(t *testing.T) {
+	h.a = alert.NewAlerter(s, &TestNotifier{})
+
+	params := url.Values{"indicator": {"thb"}}
+	body := strings.NewReader(params.Encode())
+
+	r, err := http.NewRequest("POST", "/alerts/premium", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.AddPremiumAlert)
+	handler.ServeHTTP(w, r)
+
+	if w.Result().StatusCode != http.StatusBadRequest {
+		t.Errorf("Unexpected status code %d", w.Result().StatusCode)
+	}
+}
+
+func TestAddPortfolioAlert(t *testing.T) {
+	h.a = alert.NewAlerter(s, &TestNotifier{})
+
+	params := url.Values{
+		"metric": {"pnl_percentage"}, "direction": {"below"}, "value": {"-5"}}
+	body := strings.NewReader(params.Encode())
+
+	r, err := http.NewRequest("POST", "/alerts/portfolio", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.AddPortfolioAlert)
+	handler.ServeHTTP(w, r)
+
+	if len(h.a.List()) != 1 {
+		t.Fatal("Should set an alert")
+	}
+
+	alert := h.a.List()[0]
+	expected := "PnL alert below -5.00%"
+
+	if alert.Description != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Description)
 	}
 }
 
@@ -155,15 +395,15 @@ func TestAddFundingAlert(t *testing.T) {
 	handler := http.HandlerFunc(h.AddFundingAlert)
 	handler.ServeHTTP(w, r)
 
-	if len(h.a.Alerts()) != 1 {
+	if len(h.a.List()) != 1 {
 		t.Error("Should set an alert")
 	}
 
-	alert := h.a.Alerts()[0]
+	alert := h.a.List()[0]
 	expected := "Negative funding alert"
 
-	if alert.Description() != expected {
-		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Description())
+	if alert.Description != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Description)
 	}
 }
 
@@ -183,15 +423,265 @@ func TestAddLeverageAlert(t *testing.T) {
 	handler := http.HandlerFunc(h.AddLeverageAlert)
 	handler.ServeHTTP(w, r)
 
-	if len(h.a.Alerts()) != 1 {
+	if len(h.a.List()) != 1 {
 		t.Fatal("Should set an alert")
 	}
 
-	alert := h.a.Alerts()[0]
+	alert := h.a.List()[0]
 	expected := "Leverage alert at 4.00"
 
-	if alert.Description() != expected {
-		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Description())
+	if alert.Description != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, alert.Description)
+	}
+}
+
+func TestAddRepeatingAlert(t *testing.T) {
+	h.a = alert.NewAlerter(s, &TestNotifier{})
+
+	params := url.Values{
+		"repeat":     {"true"},
+		"hysteresis": {"0.0001"},
+		"cooldown":   {"1h"}}
+	body := strings.NewReader(params.Encode())
+
+	r, err := http.NewRequest("POST", "/alerts/funding", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.AddFundingAlert)
+	handler.ServeHTTP(w, r)
+
+	alerts := h.a.List()
+	if len(alerts) != 1 || alerts[0].Repeat == nil {
+		t.Fatal("Should set a repeating alert")
+	}
+
+	if alerts[0].Repeat.Cooldown != time.Hour {
+		t.Errorf("Expected: %s, got: %s", time.Hour, alerts[0].Repeat.Cooldown)
+	}
+}
+
+func TestAddRepeatingAlertInvalidCooldown(t *testing.T) {
+	h.a = alert.NewAlerter(s, &TestNotifier{})
+
+	params := url.Values{"repeat": {"true"}, "cooldown": {"-1h"}, "value": {"4"}}
+	body := strings.NewReader(params.Encode())
+
+	r, err := http.NewRequest("POST", "/alerts/leverage", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.AddLeverageAlert)
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected: %d, got: %d", http.StatusBadRequest, w.Code)
+	}
+
+	if len(h.a.List()) != 0 {
+		t.Error("Should not set an alert")
+	}
+}
+
+func TestDeleteAlert(t *testing.T) {
+	h.a = alert.NewAlerter(s, &TestNotifier{})
+	id := h.a.AddLeverageAlert(4)
+
+	for _, expected := range []int{http.StatusOK, http.StatusNotFound} {
+		params := url.Values{"id": {strconv.Itoa(id)}}
+		body := strings.NewReader(params.Encode())
+
+		r, err := http.NewRequest("POST", "/alerts/delete", body)
+		if err != nil {
+			t.Fatal(err)
+		}
+		r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+		w := httptest.NewRecorder()
+		handler := http.HandlerFunc(h.DeleteAlert)
+		handler.ServeHTTP(w, r)
+
+		if w.Code != expected {
+			t.Errorf("Expected: %d, got: %d", expected, w.Code)
+		}
+	}
+
+	if len(h.a.List()) != 0 {
+		t.Error("Should delete alert")
+	}
+}
+
+func TestSnoozeAlertInvalidDuration(t *testing.T) {
+	h.a = alert.NewAlerter(s, &TestNotifier{})
+	id := h.a.AddLeverageAlert(4)
+
+	params := url.Values{"id": {strconv.Itoa(id)}, "duration": {"soon"}}
+	body := strings.NewReader(params.Encode())
+
+	r, err := http.NewRequest("POST", "/alerts/snooze", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.SnoozeAlert)
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusBadRequest {
+		t.Errorf("Expected: %d, got: %d", http.StatusBadRequest, w.Code)
+	}
+}
+
+func TestSnoozeAlert(t *testing.T) {
+	h.a = alert.NewAlerter(s, &TestNotifier{})
+	id := h.a.AddLeverageAlert(4)
+
+	params := url.Values{"id": {strconv.Itoa(id)}, "duration": {"1h"}}
+	body := strings.NewReader(params.Encode())
+
+	r, err := http.NewRequest("POST", "/alerts/snooze", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.SnoozeAlert)
+	handler.ServeHTTP(w, r)
+
+	if h.a.List()[0].Snoozed.Before(time.Now().Add(59 * time.Minute)) {
+		t.Error("Should snooze alert")
+	}
+}
+
+func TestRearmAlert(t *testing.T) {
+	triggering := state.NewState()
+	triggering.SetFundingRate(-0.01)
+
+	h.a = alert.NewAlerter(triggering, &TestNotifier{})
+	id := h.a.AddFundingAlert()
+	h.a.CheckAlerts()
+
+	if h.a.List()[0].Active {
+		t.Fatal("Alert should have triggered")
+	}
+
+	params := url.Values{"id": {strconv.Itoa(id)}}
+	body := strings.NewReader(params.Encode())
+
+	r, err := http.NewRequest("POST", "/alerts/rearm", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.RearmAlert)
+	handler.ServeHTTP(w, r)
+
+	if !h.a.List()[0].Active {
+		t.Error("Should re-arm alert")
+	}
+}
+
+func TestAckAlert(t *testing.T) {
+	h.a = alert.NewAlerter(s, &TestNotifier{})
+	id := h.a.AddLeverageAlert(4)
+
+	params := url.Values{"id": {strconv.Itoa(id)}}
+	body := strings.NewReader(params.Encode())
+
+	r, err := http.NewRequest("POST", "/alerts/ack", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.AckAlert)
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusConflict {
+		t.Errorf("Expected: %d, got: %d", http.StatusConflict, w.Code)
+	}
+}
+
+func TestAlerts(t *testing.T) {
+	h.a = alert.NewAlerter(s, &TestNotifier{})
+	h.a.AddLeverageAlert(4)
+	h.a.AddFundingAlert()
+
+	r, err := http.NewRequest("GET", "/alerts", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.Alerts)
+	handler.ServeHTTP(w, r)
+
+	var am []alertMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &am); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(am) != 2 || am[0].Id != 1 || am[1].Id != 2 {
+		t.Error("Should list alerts sorted by id")
+	}
+
+	if am[0].Triggered != nil || am[0].Snoozed != nil {
+		t.Error("Should omit trigger and snooze times")
+	}
+}
+
+func TestNotifications(t *testing.T) {
+	h := testHandler(t)
+	h.o.Notifier("telegram", &TestNotifier{}).Notify(alert.NewFundingAlert(s))
+
+	r, err := http.NewRequest("GET", "/notifications", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.Notifications)
+	handler.ServeHTTP(w, r)
+
+	var nm []notificationMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &nm); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(nm) != 1 || nm[0].Notifier != "telegram" || nm[0].Failed {
+		t.Errorf("Should list pending notification, got %+v", nm)
+	}
+}
+
+func TestRetryNotificationNotFound(t *testing.T) {
+	h := testHandler(t)
+
+	params := url.Values{"id": {"42"}}
+	body := strings.NewReader(params.Encode())
+
+	r, err := http.NewRequest("POST", "/notifications/retry", body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	r.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.RetryNotification)
+	handler.ServeHTTP(w, r)
+
+	if w.Code != http.StatusNotFound {
+		t.Errorf("Expected: %d, got: %d", http.StatusNotFound, w.Code)
 	}
 }
 
@@ -257,3 +747,102 @@ func TestSetLoan(t *testing.T) {
 		t.Errorf("Unexpected loan %f", h.s.GetLoan())
 	}
 }
+
+func TestHistoryInvalidMetric(t *testing.T) {
+	r, err := http.NewRequest("GET", "/history?metric=fake", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.History)
+	handler.ServeHTTP(w, r)
+
+	resp := w.Result()
+
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("Unexpected status code %d", resp.StatusCode)
+	}
+}
+
+func TestHistory(t *testing.T) {
+	h := testHandler(t)
+	now := time.Now().UTC()
+	h.hs.Append(history.Snapshot{Time: now.Add(-2 * time.Hour), Value: 1})
+	h.hs.Append(history.Snapshot{Time: now.Add(-time.Hour), Value: 2})
+
+	params := url.Values{
+		"metric": {"value"},
+		"since":  {now.Add(-90 * time.Minute).Format(time.RFC3339)}}
+
+	r, err := http.NewRequest("GET", "/history?"+params.Encode(), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.History)
+	handler.ServeHTTP(w, r)
+
+	var hm historyMessage
+	if err := json.NewDecoder(w.Result().Body).Decode(&hm); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(hm.Points) != 1 || hm.Points[0].Value != 2 {
+		t.Errorf("Unexpected points %v", hm.Points)
+	}
+}
+
+func TestPnLUSDMissingPrice(t *testing.T) {
+	r, err := http.NewRequest("GET", "/pnl/usd", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.PnLUSD)
+	handler.ServeHTTP(w, r)
+
+	resp := w.Result()
+
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("Unexpected status code %d", resp.StatusCode)
+	}
+}
+
+func TestFeeds(t *testing.T) {
+	source := func(ctx context.Context, _ func(error)) chan int {
+		ch := make(chan int)
+		go func() {
+			ch <- 1
+			<-ctx.Done()
+			close(ch)
+		}()
+		return ch
+	}
+	feed.Add(h.f, feed.USDCTHB, source, func(int) {})
+
+	for i := 0; i < 100 && h.f.Status()[feed.USDCTHB].Messages == 0; i++ {
+		time.Sleep(time.Millisecond)
+	}
+
+	r, err := http.NewRequest("GET", "/feeds", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	w := httptest.NewRecorder()
+	handler := http.HandlerFunc(h.Feeds)
+	handler.ServeHTTP(w, r)
+
+	var fr feedsResponse
+	if err := json.NewDecoder(w.Result().Body).Decode(&fr); err != nil {
+		t.Fatal(err)
+	}
+
+	item, ok := fr.Feeds[feed.USDCTHB.String()]
+	if !ok || !item.Active || item.Messages != 1 {
+		t.Errorf("Unexpected feed %+v", item)
+	}
+}
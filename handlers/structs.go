@@ -1,6 +1,10 @@
 package handlers
 
-import "time"
+import (
+	"time"
+
+	"github.com/stevenwilkin/treasury/history"
+)
 
 type pricesMessage struct {
 	Prices map[string]float64 `json:"prices"`
@@ -11,15 +15,40 @@ type assetsMessage struct {
 }
 
 type pnlMessage struct {
-	Cost          float64 `json:"cost"`
-	Value         float64 `json:"value"`
-	Pnl           float64 `json:"pnl"`
-	PnlPercentage float64 `json:"pnl_percentage"`
+	Cost          float64  `json:"cost"`
+	Value         float64  `json:"value"`
+	Pnl           float64  `json:"pnl"`
+	PnlPercentage float64  `json:"pnl_percentage"`
+	Warnings      []string `json:"warnings,omitempty"`
 }
 
 type alertMessage struct {
-	Active      bool   `json:"active"`
-	Description string `json:"description"`
+	Id          int        `json:"id"`
+	Active      bool       `json:"active"`
+	Description string     `json:"description"`
+	Created     time.Time  `json:"created"`
+	Triggered   *time.Time `json:"triggered,omitempty"`
+	Snoozed     *time.Time `json:"snoozed,omitempty"`
+	Value       float64    `json:"value"`
+	Standing    bool       `json:"standing,omitempty"`
+	Repeat      bool       `json:"repeat,omitempty"`
+	Escalating  bool       `json:"escalating,omitempty"`
+}
+
+type alertIdMessage struct {
+	Id int `json:"id"`
+}
+
+type notificationMessage struct {
+	Id          int       `json:"id"`
+	Notifier    string    `json:"notifier"`
+	AlertId     int       `json:"alert_id,omitempty"`
+	Description string    `json:"description"`
+	Created     time.Time `json:"created"`
+	Attempts    int       `json:"attempts"`
+	NextAttempt time.Time `json:"next_attempt"`
+	LastError   string    `json:"last_error,omitempty"`
+	Failed      bool      `json:"failed"`
 }
 
 type fundingMessage struct {
@@ -29,7 +58,19 @@ type fundingMessage struct {
 type feedsResponseItem struct {
 	Active     bool
 	LastUpdate time.Time
+	Errors     int
+	Restarts   int
+	LastError  string
+	Messages   int
+	Rate       float64
+	Backoff    time.Duration
+	NextRetry  time.Time
 }
 type feedsResponse struct {
 	Feeds map[string]feedsResponseItem
 }
+
+type historyMessage struct {
+	Metric string          `json:"metric"`
+	Points []history.Point `json:"points"`
+}
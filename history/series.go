@@ -0,0 +1,96 @@
+package history
+
+import (
+	"errors"
+	"strings"
+	"time"
+
+	"github.com/stevenwilkin/treasury/asset"
+	"github.com/stevenwilkin/treasury/symbol"
+	"github.com/stevenwilkin/treasury/venue"
+)
+
+type Point struct {
+	Time  time.Time `json:"time"`
+	Value float64   `json:"value"`
+}
+
+type Metric func(Snapshot) (float64, bool)
+
+func metrics() map[string]Metric {
+	return map[string]Metric{
+		"value":            func(s Snapshot) (float64, bool) { return s.Value, true },
+		"cost":             func(s Snapshot) (float64, bool) { return s.Cost, true },
+		"pnl":              func(s Snapshot) (float64, bool) { return s.Pnl, true },
+		"pnl_percentage":   func(s Snapshot) (float64, bool) { return s.PnlPercentage, true },
+		"exposure":         func(s Snapshot) (float64, bool) { return s.Exposure, true },
+		"leverage_deribit": func(s Snapshot) (float64, bool) { return s.LeverageDeribit, true },
+		"leverage_bybit":   func(s Snapshot) (float64, bool) { return s.LeverageBybit, true },
+		"funding":          func(s Snapshot) (float64, bool) { return s.Funding, true }}
+}
+
+func venueAssetMetric(v venue.Venue, a asset.Asset) Metric {
+	return func(s Snapshot) (float64, bool) {
+		return s.Assets[v.String()][a.String()], true
+	}
+}
+
+func symbolMetric(sym symbol.Symbol) Metric {
+	return func(s Snapshot) (float64, bool) {
+		p, ok := s.Symbols[sym.String()]
+		return p, ok
+	}
+}
+
+func ParseMetric(name string) (Metric, error) {
+	name = strings.ToLower(name)
+
+	if m, ok := metrics()[name]; ok {
+		return m, nil
+	}
+
+	if sym, err := symbol.FromString(name); err == nil {
+		return symbolMetric(sym), nil
+	}
+
+	parts := strings.SplitN(name, ":", 2)
+	v, err := venue.FromString(parts[0])
+	if err != nil {
+		return nil, errors.New("Invalid metric")
+	}
+
+	a := asset.BTC
+	if len(parts) == 2 {
+		if a, err = asset.FromString(parts[1]); err != nil {
+			return nil, errors.New("Invalid metric")
+		}
+	}
+
+	return venueAssetMetric(v, a), nil
+}
+
+func Series(snaps []Snapshot, m Metric, since time.Time, step time.Duration) []Point {
+	results := []Point{}
+
+	for _, snap := range snaps {
+		value, ok := m(snap)
+		if !ok {
+			continue
+		}
+
+		t := snap.Time
+		if step > 0 {
+			t = since.Add(t.Sub(since) / step * step)
+		}
+
+		n := len(results)
+		if n > 0 && results[n-1].Time.Equal(t) {
+			results[n-1].Value = value
+			continue
+		}
+
+		results = append(results, Point{Time: t, Value: value})
+	}
+
+	return results
+}
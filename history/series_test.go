@@ -0,0 +1,73 @@
+package history
+
+import (
+	"testing"
+	"time"
+)
+
+func TestParseMetricInvalid(t *testing.T) {
+	if _, err := ParseMetric("fake"); err == nil {
+		t.Error("Should return an error")
+	}
+
+	if _, err := ParseMetric("deribit:fake"); err == nil {
+		t.Error("Should return an error")
+	}
+}
+
+func TestParseMetric(t *testing.T) {
+	snap := Snapshot{
+		Value:   100,
+		Assets:  map[string]map[string]float64{"Deribit": {"BTC": 1.5, "USDT": 10}},
+		Symbols: map[string]float64{"BTCUSDT": 50000}}
+
+	tests := map[string]float64{
+		"value":        100,
+		"btcusdt":      50000,
+		"Deribit":      1.5,
+		"deribit:usdt": 10}
+
+	for name, expected := range tests {
+		m, err := ParseMetric(name)
+		if err != nil {
+			t.Fatalf("Should parse metric %s", name)
+		}
+
+		if value, _ := m(snap); value != expected {
+			t.Errorf("Expected %s to be %f, got %f", name, expected, value)
+		}
+	}
+}
+
+func TestSeries(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	snaps := []Snapshot{
+		{Time: start, Value: 1},
+		{Time: start.Add(time.Minute), Value: 2},
+		{Time: start.Add(time.Hour), Value: 3}}
+
+	m, _ := ParseMetric("value")
+
+	if points := Series(snaps, m, start, 0); len(points) != 3 {
+		t.Errorf("Expected 3 points, got %d", len(points))
+	}
+
+	points := Series(snaps, m, start, time.Hour)
+
+	if len(points) != 2 {
+		t.Fatalf("Expected 2 points, got %d", len(points))
+	}
+
+	if points[0].Value != 2 || !points[1].Time.Equal(start.Add(time.Hour)) {
+		t.Error("Should keep the last value within each step")
+	}
+}
+
+func TestSeriesSkipsMissingSymbols(t *testing.T) {
+	snaps := []Snapshot{{Time: time.Now(), Symbols: map[string]float64{}}}
+	m, _ := ParseMetric("btcusdt")
+
+	if points := Series(snaps, m, time.Now(), 0); len(points) != 0 {
+		t.Error("Should not return points")
+	}
+}
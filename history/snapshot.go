@@ -0,0 +1,60 @@
+package history
+
+import (
+	"math"
+	"time"
+
+	"github.com/stevenwilkin/treasury/state"
+)
+
+type Snapshot struct {
+	Time            time.Time                     `json:"time"`
+	Assets          map[string]map[string]float64 `json:"assets"`
+	Symbols         map[string]float64            `json:"symbols"`
+	Cost            float64                       `json:"cost"`
+	Value           float64                       `json:"value"`
+	Pnl             float64                       `json:"pnl"`
+	PnlPercentage   float64                       `json:"pnl_percentage"`
+	Exposure        float64                       `json:"exposure"`
+	LeverageDeribit float64                       `json:"leverage_deribit"`
+	LeverageBybit   float64                       `json:"leverage_bybit"`
+	Funding         float64                       `json:"funding"`
+}
+
+func finite(f float64) float64 {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return 0
+	}
+
+	return f
+}
+
+func NewSnapshot(st *state.State, t time.Time) Snapshot {
+	s := st.Snapshot()
+
+	snap := Snapshot{
+		Time:            t.UTC(),
+		Assets:          map[string]map[string]float64{},
+		Symbols:         map[string]float64{},
+		Cost:            s.GetCost(),
+		Value:           s.TotalValue(),
+		Pnl:             s.Pnl(),
+		PnlPercentage:   s.PnlPercentage(),
+		Exposure:        finite(s.Exposure()),
+		LeverageDeribit: s.GetLeverageDeribit(),
+		LeverageBybit:   s.GetLeverageBybit(),
+		Funding:         s.GetFundingRate()}
+
+	for v, balances := range s.GetAssets() {
+		snap.Assets[v.String()] = map[string]float64{}
+		for a, q := range balances {
+			snap.Assets[v.String()][a.String()] = q
+		}
+	}
+
+	for sym, p := range s.GetSymbols() {
+		snap.Symbols[sym.String()] = p
+	}
+
+	return snap
+}
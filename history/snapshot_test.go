@@ -0,0 +1,41 @@
+package history
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stevenwilkin/treasury/asset"
+	"github.com/stevenwilkin/treasury/state"
+	"github.com/stevenwilkin/treasury/symbol"
+	"github.com/stevenwilkin/treasury/venue"
+)
+
+func TestNewSnapshot(t *testing.T) {
+	s := state.NewState()
+	s.SetAsset(venue.Nexo, asset.BTC, 1)
+	s.SetSymbol(symbol.BTCTHB, 300000)
+	s.SetCost(200000)
+
+	snap := NewSnapshot(s, time.Now())
+
+	if snap.Assets["Nexo"]["BTC"] != 1 {
+		t.Error("Should contain assets")
+	}
+
+	if snap.Symbols["BTCTHB"] != 300000 {
+		t.Error("Should contain symbols")
+	}
+
+	if snap.Value != 300000 || snap.Pnl != 100000 {
+		t.Error("Should contain value and PnL")
+	}
+}
+
+func TestNewSnapshotWithoutPrices(t *testing.T) {
+	s := state.NewState()
+	s.SetSize(1000)
+
+	if snap := NewSnapshot(s, time.Now()); snap.Exposure != 0 {
+		t.Error("Should not contain non-finite values")
+	}
+}
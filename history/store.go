@@ -0,0 +1,229 @@
+package history
+
+import (
+	"bufio"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	dayFormat = "2006-01-02"
+	extension = ".jsonl"
+)
+
+type Store struct {
+	dir        string
+	raw        time.Duration
+	resolution time.Duration
+	retention  time.Duration
+	m          sync.Mutex
+}
+
+func NewStore(dir string) *Store {
+	return &Store{
+		dir:        dir,
+		raw:        7 * 24 * time.Hour,
+		resolution: time.Hour,
+		retention:  2 * 365 * 24 * time.Hour}
+}
+
+func (st *Store) SetRetention(raw, resolution, retention time.Duration) {
+	st.m.Lock()
+	defer st.m.Unlock()
+
+	st.raw = raw
+	st.resolution = resolution
+	st.retention = retention
+}
+
+func (st *Store) path(day time.Time) string {
+	return filepath.Join(st.dir, day.UTC().Format(dayFormat)+extension)
+}
+
+func (st *Store) Append(snap Snapshot) error {
+	st.m.Lock()
+	defer st.m.Unlock()
+
+	if err := os.MkdirAll(st.dir, 0755); err != nil {
+		return err
+	}
+
+	b, err := json.Marshal(snap)
+	if err != nil {
+		return err
+	}
+
+	f, err := os.OpenFile(
+		st.path(snap.Time), os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(b, '\n'))
+	return err
+}
+
+func readFile(path string) ([]Snapshot, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	results := []Snapshot{}
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	for scanner.Scan() {
+		var snap Snapshot
+		if err := json.Unmarshal(scanner.Bytes(), &snap); err != nil {
+			continue
+		}
+		results = append(results, snap)
+	}
+
+	return results, scanner.Err()
+}
+
+func (st *Store) Range(since, until time.Time) ([]Snapshot, error) {
+	st.m.Lock()
+	defer st.m.Unlock()
+
+	results := []Snapshot{}
+
+	start := since.UTC().Truncate(24 * time.Hour)
+	for day := start; !day.After(until); day = day.Add(24 * time.Hour) {
+		snaps, err := readFile(st.path(day))
+		if os.IsNotExist(err) {
+			continue
+		} else if err != nil {
+			return nil, err
+		}
+
+		for _, snap := range snaps {
+			if snap.Time.Before(since) || snap.Time.After(until) {
+				continue
+			}
+			results = append(results, snap)
+		}
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return results[i].Time.Before(results[j].Time)
+	})
+
+	return results, nil
+}
+
+func (st *Store) days() ([]time.Time, error) {
+	files, err := ioutil.ReadDir(st.dir)
+	if err != nil {
+		return nil, err
+	}
+
+	days := []time.Time{}
+	for _, file := range files {
+		name := file.Name()
+		if !strings.HasSuffix(name, extension) {
+			continue
+		}
+
+		day, err := time.Parse(dayFormat, strings.TrimSuffix(name, extension))
+		if err != nil {
+			continue
+		}
+		days = append(days, day)
+	}
+
+	return days, nil
+}
+
+func Downsample(snaps []Snapshot, resolution time.Duration) []Snapshot {
+	results := []Snapshot{}
+
+	for _, snap := range snaps {
+		n := len(results)
+		if n > 0 && results[n-1].Time.Truncate(resolution).Equal(
+			snap.Time.Truncate(resolution)) {
+			results[n-1] = snap
+			continue
+		}
+		results = append(results, snap)
+	}
+
+	return results
+}
+
+func (st *Store) downsample(path string) error {
+	snaps, err := readFile(path)
+	if err != nil {
+		return err
+	}
+
+	downsampled := Downsample(snaps, st.resolution)
+	if len(downsampled) == len(snaps) {
+		return nil
+	}
+
+	tmpFile, err := ioutil.TempFile(st.dir, "history")
+	if err != nil {
+		return err
+	}
+	defer os.RemoveAll(tmpFile.Name())
+
+	w := bufio.NewWriter(tmpFile)
+	for _, snap := range downsampled {
+		b, err := json.Marshal(snap)
+		if err != nil {
+			tmpFile.Close()
+			return err
+		}
+		w.Write(append(b, '\n'))
+	}
+
+	if err = w.Flush(); err != nil {
+		tmpFile.Close()
+		return err
+	}
+
+	if err = tmpFile.Close(); err != nil {
+		return err
+	}
+
+	return os.Rename(tmpFile.Name(), path)
+}
+
+func (st *Store) Compact(now time.Time) error {
+	st.m.Lock()
+	defer st.m.Unlock()
+
+	days, err := st.days()
+	if os.IsNotExist(err) {
+		return nil
+	} else if err != nil {
+		return err
+	}
+
+	for _, day := range days {
+		end := day.Add(24 * time.Hour)
+
+		if end.Before(now.Add(-st.retention)) {
+			if err := os.Remove(st.path(day)); err != nil {
+				return err
+			}
+		} else if end.Before(now.Add(-st.raw)) {
+			if err := st.downsample(st.path(day)); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,108 @@
+package history
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+)
+
+func tempStore(t *testing.T) *Store {
+	dir, err := ioutil.TempDir("", "history")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	return NewStore(dir)
+}
+
+func TestAppendAndRange(t *testing.T) {
+	st := tempStore(t)
+	start := time.Date(2021, 1, 1, 23, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 4; i++ {
+		snap := Snapshot{Time: start.Add(time.Duration(i) * time.Hour), Value: float64(i)}
+		if err := st.Append(snap); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	snaps, err := st.Range(start.Add(time.Hour), start.Add(2*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(snaps) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %d", len(snaps))
+	}
+
+	if snaps[0].Value != 1 || snaps[1].Value != 2 {
+		t.Error("Should return snapshots in range in order")
+	}
+}
+
+func TestRangeWithoutData(t *testing.T) {
+	st := tempStore(t)
+
+	snaps, err := st.Range(time.Now().Add(-time.Hour), time.Now())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(snaps) != 0 {
+		t.Error("Should not return snapshots")
+	}
+}
+
+func TestDownsample(t *testing.T) {
+	start := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	snaps := []Snapshot{
+		{Time: start, Value: 1},
+		{Time: start.Add(30 * time.Minute), Value: 2},
+		{Time: start.Add(time.Hour), Value: 3}}
+
+	result := Downsample(snaps, time.Hour)
+
+	if len(result) != 2 {
+		t.Fatalf("Expected 2 snapshots, got %d", len(result))
+	}
+
+	if result[0].Value != 2 {
+		t.Error("Should keep the last snapshot in each interval")
+	}
+}
+
+func TestCompact(t *testing.T) {
+	st := tempStore(t)
+	st.SetRetention(24*time.Hour, time.Hour, 72*time.Hour)
+
+	now := time.Date(2021, 1, 10, 12, 0, 0, 0, time.UTC)
+	old := time.Date(2021, 1, 1, 0, 0, 0, 0, time.UTC)
+	recent := time.Date(2021, 1, 8, 0, 0, 0, 0, time.UTC)
+	current := time.Date(2021, 1, 10, 0, 0, 0, 0, time.UTC)
+
+	for _, day := range []time.Time{old, recent, current} {
+		for i := 0; i < 4; i++ {
+			st.Append(Snapshot{Time: day.Add(time.Duration(i) * time.Minute)})
+		}
+	}
+
+	if err := st.Compact(now); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := os.Stat(st.path(old)); !os.IsNotExist(err) {
+		t.Error("Should remove snapshots beyond retention")
+	}
+
+	snaps, _ := st.Range(recent, recent.Add(24*time.Hour-time.Second))
+	if len(snaps) != 1 {
+		t.Errorf("Should downsample older snapshots, got %d", len(snaps))
+	}
+
+	snaps, _ = st.Range(current, now)
+	if len(snaps) != 4 {
+		t.Errorf("Should keep recent snapshots at full resolution, got %d", len(snaps))
+	}
+}
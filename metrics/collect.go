@@ -0,0 +1,113 @@
+package metrics
+
+import (
+	"sort"
+	"time"
+
+	"github.com/stevenwilkin/treasury/alert"
+	"github.com/stevenwilkin/treasury/feed"
+	"github.com/stevenwilkin/treasury/state"
+	"github.com/stevenwilkin/treasury/symbol"
+)
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+func sortSamples(g *Gauge) *Gauge {
+	sort.Slice(g.Samples, func(i, j int) bool {
+		return formatLabels(g.Samples[i].Labels) < formatLabels(g.Samples[j].Labels)
+	})
+
+	return g
+}
+
+func collectState(s *state.Snapshot) []*Gauge {
+	prices := NewGauge("treasury_symbol_price", "Latest price of each symbol")
+	for sym, p := range s.GetSymbols() {
+		prices.With(Labels{"symbol": sym.String()}, p)
+	}
+
+	balances := NewGauge("treasury_asset_balance", "Balance of each asset held at each venue")
+	for v, assets := range s.GetAssets() {
+		for a, q := range assets {
+			balances.With(Labels{"venue": v.String(), "asset": a.String()}, q)
+		}
+	}
+
+	leverage := NewGauge("treasury_leverage", "Leverage at each venue")
+	for v, l := range s.GetLeverages() {
+		leverage.With(Labels{"venue": v.String()}, l)
+	}
+
+	exposure := NewGauge("treasury_exposure_btc", "Long BTC exposure")
+	if s.Symbol(symbol.BTCUSDT) > 0 {
+		exposure.Set(s.Exposure())
+	}
+
+	return []*Gauge{
+		sortSamples(prices),
+		sortSamples(balances),
+		NewGauge("treasury_cost_thb", "Total cost in THB").Set(s.GetCost()),
+		NewGauge("treasury_value_thb", "Total value in THB").Set(s.TotalValue()),
+		NewGauge("treasury_pnl_thb", "PnL in THB").Set(s.Pnl()),
+		NewGauge("treasury_pnl_percentage", "PnL as a percentage of cost").Set(s.PnlPercentage()),
+		exposure,
+		sortSamples(leverage),
+		NewGauge("treasury_funding_rate", "Current funding rate").Set(s.GetFundingRate()),
+		NewGauge("treasury_thb_premium", "Premium of BTCTHB over BTCUSDT").Set(s.THBPremium()),
+		NewGauge("treasury_usdt_premium", "Premium of USDTTHB over USDTHB").Set(s.USDTPremium())}
+}
+
+func collectFeeds(f *feed.Handler, now time.Time) []*Gauge {
+	active := NewGauge("treasury_feed_active", "Whether each data feed is active")
+	lastUpdate := NewGauge("treasury_feed_last_update_seconds", "Seconds since each data feed last updated")
+	errors := NewGauge("treasury_feed_errors", "Consecutive errors of each data feed")
+
+	for fd, status := range f.Status() {
+		labels := Labels{"feed": fd.String()}
+		active.With(labels, boolValue(status.Active))
+		errors.With(labels, float64(status.Errors))
+		if !status.LastUpdate.IsZero() {
+			lastUpdate.With(labels, now.Sub(status.LastUpdate).Seconds())
+		}
+	}
+
+	return []*Gauge{
+		sortSamples(active), sortSamples(lastUpdate), sortSamples(errors)}
+}
+
+func collectAlerts(a *alert.Alerter) []*Gauge {
+	var active, inactive int
+
+	for _, al := range a.List() {
+		if al.Active {
+			active++
+		} else {
+			inactive++
+		}
+	}
+
+	return []*Gauge{
+		NewGauge("treasury_alerts", "Number of alerts").
+			With(Labels{"state": "active"}, float64(active)).
+			With(Labels{"state": "inactive"}, float64(inactive))}
+}
+
+func Collect(s *state.State, f *feed.Handler, a *alert.Alerter) []*Gauge {
+	gauges := collectState(s.Snapshot())
+
+	if f != nil {
+		gauges = append(gauges, collectFeeds(f, time.Now())...)
+	}
+
+	if a != nil {
+		gauges = append(gauges, collectAlerts(a)...)
+	}
+
+	return gauges
+}
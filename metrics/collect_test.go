@@ -0,0 +1,86 @@
+package metrics
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stevenwilkin/treasury/alert"
+	"github.com/stevenwilkin/treasury/asset"
+	"github.com/stevenwilkin/treasury/feed"
+	"github.com/stevenwilkin/treasury/state"
+	"github.com/stevenwilkin/treasury/symbol"
+	"github.com/stevenwilkin/treasury/venue"
+)
+
+type TestNotifier struct{}
+
+func (n *TestNotifier) Notify(_ alert.Alert) error { return nil }
+
+func TestCollect(t *testing.T) {
+	s := state.NewState()
+	s.SetSymbol(symbol.BTCUSDT, 20000)
+	s.SetAsset(venue.Binance, asset.BTC, 1)
+	s.SetLeverageBybit(2)
+
+	trigger := make(chan bool)
+	source := func(context.Context, func(error)) chan int {
+		ch := make(chan int)
+		go func() {
+			<-trigger
+			ch <- 1
+		}()
+		return ch
+	}
+
+	f := feed.NewHandler(context.Background())
+	feed.Add(f, feed.BTCUSDT, source, func(int) {})
+	trigger <- true
+
+	deadline := time.Now().Add(time.Second)
+	for f.Status()[feed.BTCUSDT].LastUpdate.IsZero() {
+		if time.Now().After(deadline) {
+			t.Fatal("Feed should have been updated")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	a := alert.NewAlerter(s, &TestNotifier{})
+	a.AddLeverageAlert(4)
+
+	var b bytes.Buffer
+	if err := Write(&b, Collect(s, f, a)); err != nil {
+		t.Fatal(err)
+	}
+
+	for _, expected := range []string{
+		`treasury_symbol_price{symbol="BTCUSDT"} 20000`,
+		`treasury_asset_balance{asset="BTC",venue="Binance"} 1`,
+		`treasury_exposure_btc 1`,
+		`treasury_leverage{venue="Bybit"} 2`,
+		`treasury_feed_active{feed="BTCUSDT"} 1`,
+		`treasury_feed_errors{feed="BTCUSDT"} 0`,
+		`treasury_feed_last_update_seconds{feed="BTCUSDT"} `,
+		`treasury_alerts{state="active"} 1`} {
+		if !strings.Contains(b.String(), expected) {
+			t.Errorf("Should contain '%s', got:\n%s", expected, b.String())
+		}
+	}
+}
+
+func TestCollectWithoutFeedsOrAlerts(t *testing.T) {
+	var b bytes.Buffer
+	if err := Write(&b, Collect(state.NewState(), nil, nil)); err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(b.String(), "treasury_exposure_btc") {
+		t.Error("Should not report exposure without a BTCUSDT price")
+	}
+
+	if strings.Contains(b.String(), "treasury_alerts") {
+		t.Error("Should not report alerts without an alerter")
+	}
+}
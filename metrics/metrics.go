@@ -0,0 +1,93 @@
+package metrics
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const ContentType = "text/plain; version=0.0.4; charset=utf-8"
+
+type Labels map[string]string
+
+type Sample struct {
+	Labels Labels
+	Value  float64
+}
+
+type Gauge struct {
+	Name    string
+	Help    string
+	Samples []Sample
+}
+
+func NewGauge(name, help string) *Gauge {
+	return &Gauge{Name: name, Help: help}
+}
+
+func (g *Gauge) Set(value float64) *Gauge {
+	return g.With(nil, value)
+}
+
+func (g *Gauge) With(labels Labels, value float64) *Gauge {
+	g.Samples = append(g.Samples, Sample{Labels: labels, Value: value})
+	return g
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func formatLabels(labels Labels) string {
+	if len(labels) == 0 {
+		return ""
+	}
+
+	names := make([]string, 0, len(labels))
+	for name := range labels {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	pairs := make([]string, len(names))
+	for i, name := range names {
+		pairs[i] = fmt.Sprintf(`%s="%s"`, name, labelEscaper.Replace(labels[name]))
+	}
+
+	return "{" + strings.Join(pairs, ",") + "}"
+}
+
+func formatValue(v float64) string {
+	switch {
+	case math.IsNaN(v):
+		return "NaN"
+	case math.IsInf(v, 1):
+		return "+Inf"
+	case math.IsInf(v, -1):
+		return "-Inf"
+	}
+
+	return strconv.FormatFloat(v, 'g', -1, 64)
+}
+
+func Write(w io.Writer, gauges []*Gauge) error {
+	bw := bufio.NewWriter(w)
+
+	for _, g := range gauges {
+		if len(g.Samples) == 0 {
+			continue
+		}
+
+		fmt.Fprintf(bw, "# HELP %s %s\n", g.Name, g.Help)
+		fmt.Fprintf(bw, "# TYPE %s gauge\n", g.Name)
+
+		for _, s := range g.Samples {
+			fmt.Fprintf(bw, "%s%s %s\n",
+				g.Name, formatLabels(s.Labels), formatValue(s.Value))
+		}
+	}
+
+	return bw.Flush()
+}
@@ -0,0 +1,34 @@
+package metrics
+
+import (
+	"bytes"
+	"math"
+	"testing"
+)
+
+func TestWrite(t *testing.T) {
+	gauges := []*Gauge{
+		NewGauge("treasury_value", "Total value").Set(1.5),
+		NewGauge("treasury_price", "Price").
+			With(Labels{"symbol": "BTCUSDT", "venue": "Binance"}, 20000).
+			With(Labels{"symbol": `a"b\c`}, math.Inf(1)),
+		NewGauge("treasury_empty", "Empty")}
+
+	var b bytes.Buffer
+	if err := Write(&b, gauges); err != nil {
+		t.Fatal(err)
+	}
+
+	expected := `# HELP treasury_value Total value
+# TYPE treasury_value gauge
+treasury_value 1.5
+# HELP treasury_price Price
+# TYPE treasury_price gauge
+treasury_price{symbol="BTCUSDT",venue="Binance"} 20000
+treasury_price{symbol="a\"b\\c"} +Inf
+`
+
+	if b.String() != expected {
+		t.Errorf("Expected:\n%s\ngot:\n%s", expected, b.String())
+	}
+}
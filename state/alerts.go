@@ -0,0 +1,71 @@
+package state
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/stevenwilkin/treasury/symbol"
+)
+
+type AlertSpec struct {
+	Id         int
+	Type       string
+	Params     json.RawMessage
+	Created    time.Time
+	Triggered  time.Time
+	Snoozed    time.Time
+	Value      float64
+	Active     bool
+	Repeat     bool          `json:",omitempty"`
+	Hysteresis float64       `json:",omitempty"`
+	Cooldown   time.Duration `json:",omitempty"`
+	Fired      bool          `json:",omitempty"`
+}
+
+type PriceAlert struct {
+	Symbol    symbol.Symbol
+	Direction string
+	Price     float64
+	Expires   time.Time
+	Note      string
+}
+
+func (pa *PriceAlert) UnmarshalJSON(b []byte) error {
+	var price float64
+	if err := json.Unmarshal(b, &price); err == nil {
+		*pa = PriceAlert{Symbol: symbol.BTCUSDT, Price: price}
+		return nil
+	}
+
+	type plain PriceAlert
+	return json.Unmarshal(b, (*plain)(pa))
+}
+
+func (s *State) GetAlerts() []AlertSpec {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	alerts := make([]AlertSpec, len(s.Alerts))
+	copy(alerts, s.Alerts)
+
+	return alerts
+}
+
+func (s *State) SetAlerts(alerts []AlertSpec) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.Alerts = alerts
+}
+
+func (s *State) TakeLegacyAlerts() (bool, []PriceAlert) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	funding, prices := s.FundingAlert, s.PriceAlerts
+
+	s.FundingAlert = false
+	s.PriceAlerts = nil
+
+	return funding, prices
+}
@@ -0,0 +1,236 @@
+package state
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/stevenwilkin/treasury/asset"
+	"github.com/stevenwilkin/treasury/symbol"
+	"github.com/stevenwilkin/treasury/venue"
+)
+
+type Snapshot struct {
+	time          time.Time
+	cost          float64
+	assets        map[venue.Venue]map[asset.Asset]float64
+	symbols       map[symbol.Symbol]float64
+	assetUpdates  map[venue.Venue]map[asset.Asset]Update
+	symbolUpdates map[symbol.Symbol]Update
+	fundingRate   float64
+	size          int
+	loan          float64
+	leverage      map[venue.Venue]float64
+	staleAfter    time.Duration
+}
+
+func (s *State) Snapshot() *Snapshot {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	snap := &Snapshot{
+		time:          time.Now(),
+		cost:          s.Cost,
+		assets:        map[venue.Venue]map[asset.Asset]float64{},
+		symbols:       map[symbol.Symbol]float64{},
+		assetUpdates:  map[venue.Venue]map[asset.Asset]Update{},
+		symbolUpdates: map[symbol.Symbol]Update{},
+		fundingRate:   s.FundingRate,
+		size:          s.Size,
+		loan:          s.Loan,
+		leverage:      map[venue.Venue]float64{},
+		staleAfter:    s.staleAfter}
+
+	for v, balances := range s.Assets {
+		snap.assets[v] = map[asset.Asset]float64{}
+		for a, q := range balances {
+			snap.assets[v][a] = q
+		}
+	}
+
+	for v, updates := range s.AssetUpdates {
+		snap.assetUpdates[v] = map[asset.Asset]Update{}
+		for a, u := range updates {
+			snap.assetUpdates[v][a] = u
+		}
+	}
+
+	for sym, p := range s.Symbols {
+		snap.symbols[sym] = p
+	}
+
+	for sym, u := range s.SymbolUpdates {
+		snap.symbolUpdates[sym] = u
+	}
+
+	for v, l := range s.Leverage {
+		snap.leverage[v] = l
+	}
+
+	return snap
+}
+
+func (s *Snapshot) Time() time.Time {
+	return s.time
+}
+
+func (s *Snapshot) GetCost() float64 {
+	return s.cost
+}
+
+func (s *Snapshot) GetAsset(v venue.Venue, a asset.Asset) float64 {
+	return s.assets[v][a]
+}
+
+func (s *Snapshot) GetAssets() map[venue.Venue]map[asset.Asset]float64 {
+	results := map[venue.Venue]map[asset.Asset]float64{}
+
+	for v, balances := range s.assets {
+		venueAssets := map[asset.Asset]float64{}
+
+		for a, q := range balances {
+			if strings.HasPrefix(a.String(), "USD") && q < 0.01 {
+				continue
+			}
+
+			if a == asset.BTC && q <= 0.00000100 {
+				continue
+			}
+
+			if q > 0 {
+				venueAssets[a] = q
+			}
+		}
+
+		if len(venueAssets) > 0 {
+			results[v] = venueAssets
+		}
+	}
+
+	return results
+}
+
+func (s *Snapshot) Symbol(sym symbol.Symbol) float64 {
+	return s.symbols[sym]
+}
+
+func (s *Snapshot) GetSymbols() map[symbol.Symbol]float64 {
+	results := map[symbol.Symbol]float64{}
+
+	for sym, p := range s.symbols {
+		results[sym] = p
+	}
+
+	return results
+}
+
+func (s *Snapshot) SymbolUpdate(sym symbol.Symbol) Update {
+	return s.symbolUpdates[sym]
+}
+
+func (s *Snapshot) GetFundingRate() float64 {
+	return s.fundingRate
+}
+
+func (s *Snapshot) GetSize() int {
+	return s.size
+}
+
+func (s *Snapshot) GetLoan() float64 {
+	return s.loan
+}
+
+func (s *Snapshot) GetLeverage(v venue.Venue) float64 {
+	return s.leverage[v]
+}
+
+func (s *Snapshot) GetLeverages() map[venue.Venue]float64 {
+	results := map[venue.Venue]float64{}
+
+	for v, l := range s.leverage {
+		results[v] = l
+	}
+
+	return results
+}
+
+func (s *Snapshot) GetLeverageDeribit() float64 {
+	return s.GetLeverage(venue.Deribit)
+}
+
+func (s *Snapshot) GetLeverageBybit() float64 {
+	return s.GetLeverage(venue.Bybit)
+}
+
+func (s *Snapshot) TotalValue() float64 {
+	total := 0.0
+
+	for _, balances := range s.assets {
+		for a, quantity := range balances {
+			sym, err := symbol.FromString(fmt.Sprintf("%sTHB", a))
+			if err == nil {
+				total += quantity * s.symbols[sym]
+			}
+		}
+	}
+
+	if s.loan > 0 {
+		total -= (s.loan * s.symbols[symbol.USDTHB])
+	}
+
+	return total
+}
+
+func (s *Snapshot) Pnl() float64 {
+	return s.TotalValue() - s.cost
+}
+
+func (s *Snapshot) PnlPercentage() float64 {
+	if s.cost == 0 {
+		return 0
+	}
+
+	return (s.Pnl() / s.cost) * 100
+}
+
+func (s *Snapshot) TotalEquity() float64 {
+	total := 0.0
+
+	for _, balances := range s.assets {
+		total += balances[asset.BTC]
+	}
+
+	return total
+}
+
+func (s *Snapshot) Exposure() float64 {
+	equivalentEquity := float64(s.size) / s.symbols[symbol.BTCUSDT]
+	return s.TotalEquity() - equivalentEquity
+}
+
+func (s *Snapshot) THBPremium() float64 {
+	btcthb := s.symbols[symbol.BTCTHB]
+	btcusdt := s.symbols[symbol.BTCUSDT]
+	usdtthb := s.symbols[symbol.USDTTHB]
+
+	if !(btcthb > 0 && btcusdt > 0 && usdtthb > 0) {
+		return 0
+	}
+
+	equivalent := btcthb / usdtthb
+	difference := equivalent - btcusdt
+	percentage := difference / btcusdt
+
+	return percentage
+}
+
+func (s *Snapshot) USDTPremium() float64 {
+	usdthb := s.symbols[symbol.USDTHB]
+	usdtthb := s.symbols[symbol.USDTTHB]
+
+	if !(usdthb > 0 && usdtthb > 0) {
+		return 0
+	}
+
+	return (usdtthb - usdthb) / usdthb
+}
@@ -0,0 +1,77 @@
+package state
+
+import (
+	"sync"
+	"testing"
+
+	"github.com/stevenwilkin/treasury/asset"
+	"github.com/stevenwilkin/treasury/symbol"
+	"github.com/stevenwilkin/treasury/venue"
+)
+
+func TestSnapshot(t *testing.T) {
+	s := NewState()
+	s.SetCost(100)
+	s.SetAsset(venue.Nexo, asset.BTC, 1)
+	s.SetSymbol(symbol.BTCTHB, 1000)
+
+	snap := s.Snapshot()
+
+	if snap.GetCost() != 100 || snap.TotalValue() != 1000 || snap.Pnl() != 900 {
+		t.Error("Should reflect state when taken")
+	}
+
+	s.SetCost(200)
+	s.SetAsset(venue.Nexo, asset.BTC, 2)
+	s.SetSymbol(symbol.BTCTHB, 2000)
+
+	if snap.GetCost() != 100 || snap.TotalValue() != 1000 || snap.Pnl() != 900 {
+		t.Error("Should not change when state changes")
+	}
+
+	snap.GetAssets()[venue.Nexo][asset.BTC] = 3
+	snap.GetSymbols()[symbol.BTCTHB] = 3000
+
+	if snap.GetAsset(venue.Nexo, asset.BTC) != 1 || snap.Symbol(symbol.BTCTHB) != 1000 {
+		t.Error("Should not be modified through returned maps")
+	}
+}
+
+func TestSnapshotWarnings(t *testing.T) {
+	s := NewState()
+	s.SetAsset(venue.Nexo, asset.BTC, 1)
+
+	if warnings := s.Snapshot().ValueWarnings(); len(warnings) != 1 {
+		t.Errorf("Expected 1 warning, got: %v", warnings)
+	}
+}
+
+func TestConcurrentAccess(t *testing.T) {
+	s := NewState()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+
+			for j := 0; j < 100; j++ {
+				s.SetCost(float64(j))
+				s.SetFundingRate(float64(j))
+				s.SetSize(j)
+				s.SetLoan(float64(j))
+				s.SetLeverage(venue.Deribit, float64(j))
+				s.SetAsset(venue.Nexo, asset.BTC, float64(i))
+				s.SetSymbol(symbol.BTCUSDT, float64(j+1))
+
+				snap := s.Snapshot()
+				snap.Exposure()
+				snap.ValueWarnings()
+				s.Pnl()
+				s.GetFundingRate()
+			}
+		}(i)
+	}
+
+	wg.Wait()
+}
@@ -2,11 +2,11 @@ package state
 
 import (
 	"encoding/json"
-	"fmt"
 	"io/ioutil"
 	"os"
-	"strings"
+	"path/filepath"
 	"sync"
+	"time"
 
 	"github.com/stevenwilkin/treasury/asset"
 	"github.com/stevenwilkin/treasury/symbol"
@@ -18,26 +18,48 @@ type State struct {
 	Cost            float64
 	Assets          map[venue.Venue]map[asset.Asset]float64
 	Symbols         map[symbol.Symbol]float64
+	AssetUpdates    map[venue.Venue]map[asset.Asset]Update
+	SymbolUpdates   map[symbol.Symbol]Update
 	FundingRate     float64
 	Size            int
 	Loan            float64
-	FundingAlert    bool
-	PriceAlerts     []float64
-	LeverageDeribit float64
-	LeverageBybit   float64
+	Alerts          []AlertSpec
+	FundingAlert    bool         `json:",omitempty"`
+	PriceAlerts     []PriceAlert `json:",omitempty"`
+	Leverage        map[venue.Venue]float64
+	LeverageDeribit float64 `json:",omitempty"`
+	LeverageBybit   float64 `json:",omitempty"`
+	path            string
+	staleAfter      time.Duration
 }
 
 const (
-	statePath = "/var/lib/treasuryd/state.json"
+	defaultPath = "/var/lib/treasuryd/state.json"
 )
 
 func NewState() *State {
 	return &State{
-		Assets:  map[venue.Venue]map[asset.Asset]float64{},
-		Symbols: map[symbol.Symbol]float64{}}
+		Assets:        map[venue.Venue]map[asset.Asset]float64{},
+		Symbols:       map[symbol.Symbol]float64{},
+		AssetUpdates:  map[venue.Venue]map[asset.Asset]Update{},
+		SymbolUpdates: map[symbol.Symbol]Update{},
+		Leverage:      map[venue.Venue]float64{},
+		path:          defaultPath,
+		staleAfter:    defaultStaleAfter}
+}
+
+func (s *State) SetPath(path string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.path = path
 }
 
 func (s *State) SetAsset(v venue.Venue, a asset.Asset, q float64) {
+	s.SetAssetFrom(v, a, q, Manual)
+}
+
+func (s *State) SetAssetFrom(v venue.Venue, a asset.Asset, q float64, source string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
@@ -45,7 +67,12 @@ func (s *State) SetAsset(v venue.Venue, a asset.Asset, q float64) {
 		s.Assets[v] = map[asset.Asset]float64{}
 	}
 
+	if _, ok := s.AssetUpdates[v]; !ok {
+		s.AssetUpdates[v] = map[asset.Asset]Update{}
+	}
+
 	s.Assets[v][a] = q
+	s.AssetUpdates[v][a] = Update{Time: time.Now(), Source: source}
 }
 
 func (s *State) GetAsset(v venue.Venue, a asset.Asset) float64 {
@@ -56,41 +83,19 @@ func (s *State) GetAsset(v venue.Venue, a asset.Asset) float64 {
 }
 
 func (s *State) GetAssets() map[venue.Venue]map[asset.Asset]float64 {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	results := map[venue.Venue]map[asset.Asset]float64{}
-
-	for v, balances := range s.Assets {
-		venueAssets := map[asset.Asset]float64{}
-
-		for a, q := range balances {
-			if strings.HasPrefix(a.String(), "USD") && q < 0.01 {
-				continue
-			}
-
-			if a == asset.BTC && q <= 0.00000100 {
-				continue
-			}
-
-			if q > 0 {
-				venueAssets[a] = q
-			}
-		}
-
-		if len(venueAssets) > 0 {
-			results[v] = venueAssets
-		}
-	}
-
-	return results
+	return s.Snapshot().GetAssets()
 }
 
 func (s *State) SetSymbol(sym symbol.Symbol, v float64) {
+	s.SetSymbolFrom(sym, v, Manual)
+}
+
+func (s *State) SetSymbolFrom(sym symbol.Symbol, v float64, source string) {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
 	s.Symbols[sym] = v
+	s.SymbolUpdates[sym] = Update{Time: time.Now(), Source: source}
 }
 
 func (s *State) Symbol(sym symbol.Symbol) float64 {
@@ -114,144 +119,130 @@ func (s *State) GetSymbols() map[symbol.Symbol]float64 {
 }
 
 func (s *State) SetCost(c float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.Cost = c
 }
 
+func (s *State) GetCost() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.Cost
+}
+
 func (s *State) SetFundingRate(funding float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.FundingRate = funding
 }
 
 func (s *State) GetFundingRate() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	return s.FundingRate
 }
 
 func (s *State) SetSize(size int) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.Size = size
 }
 
 func (s *State) GetLoan() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	return s.Loan
 }
 
 func (s *State) SetLoan(loan float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
 	s.Loan = loan
 }
 
-func (s *State) GetLeverageDeribit() float64 {
-	return s.LeverageDeribit
-}
+func (s *State) GetLeverage(v venue.Venue) float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-func (s *State) SetLeverageDeribit(leverage float64) {
-	s.LeverageDeribit = leverage
-}
-func (s *State) GetLeverageBybit() float64 {
-	return s.LeverageBybit
+	return s.Leverage[v]
 }
 
-func (s *State) SetLeverageBybit(leverage float64) {
-	s.LeverageBybit = leverage
-}
+func (s *State) SetLeverage(v venue.Venue, leverage float64) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
 
-func (s *State) GetSize() int {
-	return s.Size
+	s.Leverage[v] = leverage
 }
 
-func (s *State) TotalValue() float64 {
+func (s *State) GetLeverages() map[venue.Venue]float64 {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	total := 0.0
+	results := map[venue.Venue]float64{}
 
-	for _, balances := range s.Assets {
-		for a, quantity := range balances {
-			sym, err := symbol.FromString(fmt.Sprintf("%sTHB", a))
-			if err == nil {
-				total += quantity * s.Symbols[sym]
-			}
-		}
+	for v, l := range s.Leverage {
+		results[v] = l
 	}
 
-	if s.Loan > 0 {
-		total -= (s.Loan * s.Symbols[symbol.USDTHB])
-	}
+	return results
+}
 
-	return total
+func (s *State) GetLeverageDeribit() float64 {
+	return s.GetLeverage(venue.Deribit)
 }
 
-func (s *State) Pnl() float64 {
-	return s.TotalValue() - s.Cost
+func (s *State) SetLeverageDeribit(leverage float64) {
+	s.SetLeverage(venue.Deribit, leverage)
 }
 
-func (s *State) PnlPercentage() float64 {
-	if s.Cost == 0 {
-		return 0
-	}
+func (s *State) GetLeverageBybit() float64 {
+	return s.GetLeverage(venue.Bybit)
+}
 
-	return (s.Pnl() / s.Cost) * 100
+func (s *State) SetLeverageBybit(leverage float64) {
+	s.SetLeverage(venue.Bybit, leverage)
 }
 
-func (s *State) TotalEquity() float64 {
+func (s *State) GetSize() int {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	total := 0.0
-
-	for _, balances := range s.Assets {
-		total += balances[asset.BTC]
-	}
-
-	return total
+	return s.Size
 }
 
-func (s *State) Exposure() float64 {
-	equivalentEquity := float64(s.GetSize()) / s.Symbol(symbol.BTCUSDT)
-	return s.TotalEquity() - equivalentEquity
+func (s *State) TotalValue() float64 {
+	return s.Snapshot().TotalValue()
 }
 
-func (s *State) THBPremium() float64 {
-	btcthb := s.Symbol(symbol.BTCTHB)
-	btcusdt := s.Symbol(symbol.BTCUSDT)
-	usdtthb := s.Symbol(symbol.USDTTHB)
-
-	if !(btcthb > 0 && btcusdt > 0 && usdtthb > 0) {
-		return 0
-	}
-
-	equivalent := btcthb / usdtthb
-	difference := equivalent - btcusdt
-	percentage := difference / btcusdt
-
-	return percentage
+func (s *State) Pnl() float64 {
+	return s.Snapshot().Pnl()
 }
 
-func (s *State) USDTPremium() float64 {
-	usdthb := s.Symbol(symbol.USDTHB)
-	usdtthb := s.Symbol(symbol.USDTTHB)
-
-	if !(usdthb > 0 && usdtthb > 0) {
-		return 0
-	}
-
-	return (usdtthb - usdthb) / usdthb
+func (s *State) PnlPercentage() float64 {
+	return s.Snapshot().PnlPercentage()
 }
 
-func (s *State) GetPriceAlerts() []float64 {
-	s.mu.Lock()
-	defer s.mu.Unlock()
-
-	return s.PriceAlerts
+func (s *State) TotalEquity() float64 {
+	return s.Snapshot().TotalEquity()
 }
 
-func (s *State) SetPriceAlerts(alerts []float64) {
-	s.PriceAlerts = alerts
+func (s *State) Exposure() float64 {
+	return s.Snapshot().Exposure()
 }
 
-func (s *State) GetFundingAlert() bool {
-	return s.FundingAlert
+func (s *State) THBPremium() float64 {
+	return s.Snapshot().THBPremium()
 }
 
-func (s *State) SetFundingAlert(alert bool) {
-	s.FundingAlert = alert
+func (s *State) USDTPremium() float64 {
+	return s.Snapshot().USDTPremium()
 }
 
 func (s *State) Save() error {
@@ -263,17 +254,21 @@ func (s *State) Save() error {
 		return err
 	}
 
-	tmpFile, err := ioutil.TempFile("/tmp/", "state")
+	tmpFile, err := ioutil.TempFile(filepath.Dir(s.path), "state")
 	if err != nil {
 		return err
 	}
 
-	err = ioutil.WriteFile(tmpFile.Name(), b, os.ModePerm)
+	_, err = tmpFile.Write(b)
+	if closeErr := tmpFile.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
+		os.RemoveAll(tmpFile.Name())
 		return err
 	}
 
-	err = os.Rename(tmpFile.Name(), statePath)
+	err = os.Rename(tmpFile.Name(), s.path)
 	if err != nil {
 		os.RemoveAll(tmpFile.Name())
 		return err
@@ -283,7 +278,10 @@ func (s *State) Save() error {
 }
 
 func (s *State) Load() error {
-	stateJSON, err := ioutil.ReadFile(statePath)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	stateJSON, err := ioutil.ReadFile(s.path)
 	if err != nil {
 		return err
 	}
@@ -293,5 +291,24 @@ func (s *State) Load() error {
 		return err
 	}
 
+	s.migrateLeverage()
+
 	return nil
 }
+
+func (s *State) migrateLeverage() {
+	if s.Leverage == nil {
+		s.Leverage = map[venue.Venue]float64{}
+	}
+
+	for v, leverage := range map[venue.Venue]float64{
+		venue.Deribit: s.LeverageDeribit,
+		venue.Bybit:   s.LeverageBybit} {
+		if _, ok := s.Leverage[v]; !ok && leverage != 0 {
+			s.Leverage[v] = leverage
+		}
+	}
+
+	s.LeverageDeribit = 0
+	s.LeverageBybit = 0
+}
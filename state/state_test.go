@@ -1,7 +1,12 @@
 package state
 
 import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/stevenwilkin/treasury/asset"
@@ -300,32 +305,124 @@ func TestUSDTPremium(t *testing.T) {
 	}
 }
 
-func TestFundingAlert(t *testing.T) {
+func TestAlerts(t *testing.T) {
 	s := NewState()
 
-	if s.GetFundingAlert() {
-		t.Error("Expected not to have funding alert")
+	if len(s.GetAlerts()) != 0 {
+		t.Error("Expected not to have alerts")
 	}
 
-	s.SetFundingAlert(true)
+	alerts := []AlertSpec{{Id: 1, Type: "funding"}, {Id: 2, Type: "leverage"}}
 
-	if !s.GetFundingAlert() {
-		t.Error("Expected to have funding alert")
+	s.SetAlerts(alerts)
+
+	if len(s.GetAlerts()) != len(alerts) {
+		t.Error("Expected to have alerts")
+	}
+
+	s.GetAlerts()[0].Active = true
+
+	if s.GetAlerts()[0].Active {
+		t.Error("Expected alerts to be copied")
+	}
+}
+
+func TestTakeLegacyAlerts(t *testing.T) {
+	var s State
+	b := []byte(`{"FundingAlert": true, "PriceAlerts": [10000]}`)
+	if err := json.Unmarshal(b, &s); err != nil {
+		t.Fatal(err)
+	}
+
+	funding, prices := s.TakeLegacyAlerts()
+
+	if !funding || len(prices) != 1 {
+		t.Error("Expected to have legacy alerts")
+	}
+
+	if funding, prices = s.TakeLegacyAlerts(); funding || len(prices) != 0 {
+		t.Error("Expected legacy alerts to be cleared")
+	}
+
+	b, err := json.Marshal(&s)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if strings.Contains(string(b), "PriceAlerts") {
+		t.Error("Expected not to save legacy alerts")
 	}
 }
 
-func TestPriceAlerts(t *testing.T) {
+func TestSaveAndLoad(t *testing.T) {
+	dir, err := ioutil.TempDir("", "state")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	path := filepath.Join(dir, "state.json")
+
 	s := NewState()
+	s.SetPath(path)
+	s.SetCost(123.45)
+	s.SetAsset(venue.Nexo, asset.BTC, 1.2)
 
-	if len(s.GetPriceAlerts()) != 0 {
-		t.Error("Expected not to have prices alerts")
+	if err := s.Save(); err != nil {
+		t.Fatal(err)
 	}
 
-	alerts := []float64{10000, 20000}
+	loaded := NewState()
+	loaded.SetPath(path)
 
-	s.SetPriceAlerts(alerts)
+	if err := loaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if loaded.Cost != 123.45 || loaded.GetAsset(venue.Nexo, asset.BTC) != 1.2 {
+		t.Error("Should load saved state")
+	}
+
+	files, _ := ioutil.ReadDir(dir)
+	if len(files) != 1 {
+		t.Error("Should not leave temporary files")
+	}
+}
+
+func TestLoadLegacyPriceAlerts(t *testing.T) {
+	var s State
+	if err := json.Unmarshal([]byte(`{"PriceAlerts": [10000]}`), &s); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(s.PriceAlerts) != 1 {
+		t.Fatal("Expected to have a price alert")
+	}
+
+	pa := s.PriceAlerts[0]
+	if pa.Symbol != symbol.BTCUSDT || pa.Price != 10000 || pa.Direction != "" {
+		t.Errorf("Unexpected price alert %+v", pa)
+	}
+}
+
+func TestLoadLegacyLeverage(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.json")
+	legacy := `{"LeverageDeribit": 2, "LeverageBybit": 3}`
+	if err := ioutil.WriteFile(path, []byte(legacy), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	s := NewState()
+	s.SetPath(path)
+	if err := s.Load(); err != nil {
+		t.Fatal(err)
+	}
+
+	if s.GetLeverageDeribit() != 2 || s.GetLeverageBybit() != 3 {
+		t.Errorf("Unexpected leverage %v", s.GetLeverages())
+	}
 
-	if len(s.GetPriceAlerts()) != len(alerts) {
-		t.Error("Expected to have prices alerts")
+	if s.LeverageDeribit != 0 || s.LeverageBybit != 0 {
+		t.Error("Should clear legacy leverage")
 	}
 }
@@ -0,0 +1,158 @@
+package state
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/stevenwilkin/treasury/asset"
+	"github.com/stevenwilkin/treasury/symbol"
+)
+
+const (
+	Manual            = "manual"
+	defaultStaleAfter = 5 * time.Minute
+)
+
+type Update struct {
+	Time   time.Time
+	Source string
+}
+
+func (u Update) stale(staleAfter time.Duration) bool {
+	if u.Source == Manual || u.Source == "" {
+		return false
+	}
+
+	return time.Since(u.Time) > staleAfter
+}
+
+func (s *State) SetStaleAfter(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.staleAfter = d
+}
+
+func (s *State) SymbolUpdate(sym symbol.Symbol) Update {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.SymbolUpdates[sym]
+}
+
+func (s *Snapshot) symbolWarning(sym symbol.Symbol) string {
+	if s.symbols[sym] == 0 {
+		return fmt.Sprintf("%s missing", sym)
+	}
+
+	if u := s.symbolUpdates[sym]; u.stale(s.staleAfter) {
+		return fmt.Sprintf("%s stale from %s since %s",
+			sym, u.Source, u.Time.Format(time.RFC3339))
+	}
+
+	return ""
+}
+
+func (s *Snapshot) symbolWarnings(syms ...symbol.Symbol) []string {
+	warnings := []string{}
+
+	for _, sym := range syms {
+		if w := s.symbolWarning(sym); w != "" {
+			warnings = append(warnings, w)
+		}
+	}
+
+	return warnings
+}
+
+func (s *Snapshot) SymbolWarnings(syms ...symbol.Symbol) []string {
+	return s.symbolWarnings(syms...)
+}
+
+func (s *State) SymbolWarnings(syms ...symbol.Symbol) []string {
+	return s.Snapshot().SymbolWarnings(syms...)
+}
+
+func (s *Snapshot) assetWarnings(assets ...asset.Asset) []string {
+	warnings := []string{}
+
+	for v, updates := range s.assetUpdates {
+		for a, u := range updates {
+			if len(assets) > 0 && !containsAsset(assets, a) {
+				continue
+			}
+
+			if s.assets[v][a] != 0 && u.stale(s.staleAfter) {
+				warnings = append(warnings, fmt.Sprintf("%s %s stale from %s since %s",
+					v, a, u.Source, u.Time.Format(time.RFC3339)))
+			}
+		}
+	}
+
+	return warnings
+}
+
+func containsAsset(assets []asset.Asset, a asset.Asset) bool {
+	for _, candidate := range assets {
+		if candidate == a {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (s *Snapshot) valueSymbols() []symbol.Symbol {
+	required := map[symbol.Symbol]bool{}
+
+	for _, balances := range s.assets {
+		for a, quantity := range balances {
+			if quantity == 0 {
+				continue
+			}
+
+			sym, err := symbol.FromString(fmt.Sprintf("%sTHB", a))
+			if err == nil {
+				required[sym] = true
+			}
+		}
+	}
+
+	if s.loan > 0 {
+		required[symbol.USDTHB] = true
+	}
+
+	results := []symbol.Symbol{}
+	for sym := range required {
+		results = append(results, sym)
+	}
+
+	return results
+}
+
+func (s *Snapshot) ValueWarnings() []string {
+	warnings := s.symbolWarnings(s.valueSymbols()...)
+	return append(warnings, s.assetWarnings()...)
+}
+
+func (s *State) ValueWarnings() []string {
+	return s.Snapshot().ValueWarnings()
+}
+
+func (s *Snapshot) ExposureWarnings() []string {
+	warnings := s.symbolWarnings(symbol.BTCUSDT)
+	return append(warnings, s.assetWarnings(asset.BTC)...)
+}
+
+func (s *State) ExposureWarnings() []string {
+	return s.Snapshot().ExposureWarnings()
+}
+
+func (s *Snapshot) PremiumWarnings() []string {
+	return s.SymbolWarnings(
+		symbol.BTCTHB, symbol.BTCUSDT, symbol.USDTTHB, symbol.USDTHB)
+}
+
+func (s *State) PremiumWarnings() []string {
+	return s.Snapshot().PremiumWarnings()
+}
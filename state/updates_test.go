@@ -0,0 +1,79 @@
+package state
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stevenwilkin/treasury/asset"
+	"github.com/stevenwilkin/treasury/symbol"
+	"github.com/stevenwilkin/treasury/venue"
+)
+
+func TestSetSymbolFromRecordsUpdate(t *testing.T) {
+	s := NewState()
+	s.SetSymbolFrom(symbol.BTCTHB, 300000, "bitkub")
+
+	u := s.SymbolUpdate(symbol.BTCTHB)
+
+	if u.Source != "bitkub" || u.Time.IsZero() {
+		t.Error("Should record source and time of update")
+	}
+}
+
+func TestSymbolWarningsMissing(t *testing.T) {
+	s := NewState()
+
+	warnings := s.SymbolWarnings(symbol.USDTHB)
+
+	if len(warnings) != 1 || warnings[0] != "USDTHB missing" {
+		t.Errorf("Unexpected warnings %v", warnings)
+	}
+}
+
+func TestSymbolWarningsStale(t *testing.T) {
+	s := NewState()
+	s.SetStaleAfter(time.Millisecond)
+	s.SetSymbolFrom(symbol.USDTHB, 31, "xe")
+	s.SetSymbol(symbol.BTCTHB, 300000)
+
+	time.Sleep(2 * time.Millisecond)
+
+	if len(s.SymbolWarnings(symbol.USDTHB)) != 1 {
+		t.Error("Should warn of stale symbol")
+	}
+
+	if len(s.SymbolWarnings(symbol.BTCTHB)) != 0 {
+		t.Error("Manually set symbols should not become stale")
+	}
+}
+
+func TestValueWarnings(t *testing.T) {
+	s := NewState()
+	s.SetAsset(venue.Nexo, asset.BTC, 1)
+	s.SetAsset(venue.Nexo, asset.USDC, 0)
+	s.SetLoan(1000)
+
+	if len(s.ValueWarnings()) != 2 {
+		t.Errorf("Should warn of missing symbols, got %v", s.ValueWarnings())
+	}
+
+	s.SetSymbol(symbol.BTCTHB, 300000)
+	s.SetSymbol(symbol.USDTHB, 31)
+
+	if len(s.ValueWarnings()) != 0 {
+		t.Errorf("Should not have warnings, got %v", s.ValueWarnings())
+	}
+}
+
+func TestExposureWarningsStaleAsset(t *testing.T) {
+	s := NewState()
+	s.SetStaleAfter(time.Millisecond)
+	s.SetSymbol(symbol.BTCUSDT, 10000)
+	s.SetAssetFrom(venue.Deribit, asset.BTC, 1, "deribit")
+
+	time.Sleep(2 * time.Millisecond)
+
+	if len(s.ExposureWarnings()) != 1 {
+		t.Errorf("Should warn of stale asset, got %v", s.ExposureWarnings())
+	}
+}
@@ -0,0 +1,517 @@
+package telegram
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/stevenwilkin/treasury/alert"
+	"github.com/stevenwilkin/treasury/asset"
+	"github.com/stevenwilkin/treasury/feed"
+	"github.com/stevenwilkin/treasury/state"
+	"github.com/stevenwilkin/treasury/symbol"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const help = `/pnl - PnL
+/assets - Assets
+/prices - Current prices
+/exposure - BTC long exposure
+/leverage - Account leverage
+/funding - Funding
+/feeds - Data feeds
+/alerts - Alerts
+/alerts price [symbol above|below] value
+/alerts move symbol percentage window [up|down|either]
+/alerts funding
+/alerts leverage value
+/alerts exposure value [usd]
+/alerts pnl above|below value [usd|percent]
+/alerts value above|below value [usd]
+/alerts delete|rearm|ack id
+/alerts snooze id duration
+/alerts clear`
+
+var (
+	errUsage        = errors.New("Invalid command, see /help")
+	errUnknownReply = errors.New("Not a recent alert, use /ack id")
+)
+
+type Bot struct {
+	telegram *Telegram
+	state    *state.State
+	alerter  *alert.Alerter
+	feeds    *feed.Handler
+}
+
+func warnings(lines []string, warnings []string) []string {
+	for _, warning := range warnings {
+		lines = append(lines, "Warning: "+warning)
+	}
+
+	return lines
+}
+
+func (b *Bot) pnl() string {
+	snap := b.state.Snapshot()
+
+	lines := []string{
+		fmt.Sprintf("Cost:  %f", snap.GetCost()),
+		fmt.Sprintf("Value: %f", snap.TotalValue()),
+		fmt.Sprintf("PnL:   %f", snap.Pnl()),
+		fmt.Sprintf("PnL %%: %.2f", snap.PnlPercentage())}
+
+	return strings.Join(warnings(lines, snap.ValueWarnings()), "\n")
+}
+
+func (b *Bot) assets() string {
+	venues := []string{}
+
+	for v, balances := range b.state.GetAssets() {
+		lines := []string{}
+		for a, q := range balances {
+			if a == asset.BTC {
+				lines = append(lines, fmt.Sprintf("  %s: %.8f", a, q))
+			} else {
+				lines = append(lines, fmt.Sprintf("  %s: %.2f", a, q))
+			}
+		}
+		sort.Strings(lines)
+
+		venues = append(venues, v.String()+"\n"+strings.Join(lines, "\n"))
+	}
+	sort.Strings(venues)
+
+	if len(venues) == 0 {
+		return "No assets"
+	}
+
+	return strings.Join(venues, "\n")
+}
+
+func (b *Bot) prices() string {
+	lines := []string{}
+	for s, p := range b.state.GetSymbols() {
+		lines = append(lines, fmt.Sprintf("%s: %f", s, p))
+	}
+	sort.Strings(lines)
+
+	if len(lines) == 0 {
+		return "No prices"
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (b *Bot) exposure() string {
+	snap := b.state.Snapshot()
+
+	if snap.Symbol(symbol.BTCUSDT) == 0 {
+		return "BTCUSDT missing"
+	}
+
+	lines := []string{fmt.Sprintf("%f", snap.Exposure())}
+
+	return strings.Join(warnings(lines, snap.ExposureWarnings()), "\n")
+}
+
+func (b *Bot) leverage() string {
+	snap := b.state.Snapshot()
+	lines := []string{}
+
+	if l := snap.GetLeverageDeribit(); l > 0 {
+		lines = append(lines, fmt.Sprintf("Deribit: %.2f", l))
+	}
+	if l := snap.GetLeverageBybit(); l > 0 {
+		lines = append(lines, fmt.Sprintf("Bybit:   %.2f", l))
+	}
+
+	if len(lines) == 0 {
+		return "No leverage"
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (b *Bot) funding() string {
+	return fmt.Sprintf("%f%%", b.state.GetFundingRate()*100)
+}
+
+func (b *Bot) feedStatus() string {
+	if b.feeds == nil {
+		return "No feeds"
+	}
+
+	lines := []string{}
+	for f, status := range b.feeds.Status() {
+		line := fmt.Sprintf("%s  Inactive", f)
+
+		if status.Active {
+			line = fmt.Sprintf("%s  Active  Never", f)
+			if !status.LastUpdate.IsZero() {
+				line = fmt.Sprintf("%s  Active  %.2fs", f, time.Since(status.LastUpdate).Seconds())
+			}
+		}
+
+		lines = append(lines, line)
+	}
+	sort.Strings(lines)
+
+	if len(lines) == 0 {
+		return "No feeds"
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func (b *Bot) alerts() string {
+	lines := []string{}
+
+	for _, info := range b.alerter.List() {
+		active := "Active"
+		if !info.Active {
+			active = "Inactive"
+		}
+
+		details := fmt.Sprintf("last %.2f", info.Value)
+		if info.Repeat != nil {
+			details += ", repeating"
+		}
+		if info.Escalating {
+			details += ", escalating"
+		}
+		if !info.Triggered.IsZero() {
+			details += fmt.Sprintf(", triggered %s",
+				info.Triggered.Local().Format("2006-01-02 15:04"))
+		}
+		if info.Snoozed.After(time.Now()) {
+			details += fmt.Sprintf(", snoozed until %s",
+				info.Snoozed.Local().Format("2006-01-02 15:04"))
+		}
+
+		lines = append(lines, fmt.Sprintf("%d %s - %s (%s)",
+			info.Id, active, info.Description, details))
+	}
+
+	if len(lines) == 0 {
+		return "No alerts"
+	}
+
+	return strings.Join(lines, "\n")
+}
+
+func parseValue(s string) (float64, error) {
+	v, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		return 0, fmt.Errorf("Invalid value: %s", s)
+	}
+
+	return v, nil
+}
+
+func (b *Bot) addPriceAlert(args []string) (int, error) {
+	if len(args) != 1 && len(args) != 3 {
+		return 0, errUsage
+	}
+
+	v, err := parseValue(args[len(args)-1])
+	if err != nil {
+		return 0, err
+	}
+
+	sym := symbol.BTCUSDT
+	d := alert.Crosses
+
+	if len(args) == 3 {
+		if sym, err = symbol.FromString(args[0]); err != nil {
+			return 0, err
+		}
+
+		if d, err = alert.ParseDirection(args[1]); err != nil {
+			return 0, err
+		}
+	}
+
+	return b.alerter.AddPriceAlert(sym, d, v, time.Time{}, ""), nil
+}
+
+func (b *Bot) addMoveAlert(args []string) (int, error) {
+	if len(args) != 3 && len(args) != 4 {
+		return 0, errUsage
+	}
+
+	sym, err := symbol.FromString(args[0])
+	if err != nil {
+		return 0, err
+	}
+
+	percentage, err := parseValue(args[1])
+	if err != nil || percentage <= 0 {
+		return 0, fmt.Errorf("Invalid percentage: %s", args[1])
+	}
+
+	window, err := time.ParseDuration(args[2])
+	if err != nil || window <= 0 {
+		return 0, fmt.Errorf("Invalid window: %s", args[2])
+	}
+
+	m := alert.Either
+	if len(args) == 4 {
+		if m, err = alert.ParseMove(args[3]); err != nil {
+			return 0, err
+		}
+	}
+
+	return b.alerter.AddMoveAlert(sym, m, percentage, window), nil
+}
+
+func (b *Bot) addThresholdAlert(kind string, args []string) (int, error) {
+	if len(args) < 1 || len(args) > 2 || (len(args) == 2 && kind != "exposure") {
+		return 0, errUsage
+	}
+
+	v, err := parseValue(args[0])
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("Invalid value: %s", args[0])
+	}
+
+	if kind == "leverage" {
+		return b.alerter.AddLeverageAlert(v), nil
+	}
+
+	usd := len(args) == 2 && args[1] == "usd"
+	if len(args) == 2 && !usd {
+		return 0, errUsage
+	}
+
+	return b.alerter.AddExposureAlert(v, usd), nil
+}
+
+func (b *Bot) addPortfolioAlert(m alert.PortfolioMetric, args []string) (int, error) {
+	if len(args) != 2 && len(args) != 3 {
+		return 0, errUsage
+	}
+
+	d, err := alert.ParseDirection(args[0])
+	if err != nil {
+		return 0, fmt.Errorf("Invalid direction: %s", args[0])
+	}
+
+	v, err := parseValue(args[1])
+	if err != nil {
+		return 0, err
+	}
+
+	usd := false
+	if len(args) == 3 {
+		switch {
+		case args[2] == "usd":
+			usd = true
+		case args[2] == "percent" && m == alert.PnlMetric:
+			m = alert.PnlPercentageMetric
+		default:
+			return 0, errUsage
+		}
+	}
+
+	return b.alerter.AddPortfolioAlert(m, usd, d, v), nil
+}
+
+func (b *Bot) manageAlert(command string, args []string) error {
+	if len(args) < 1 {
+		return errUsage
+	}
+
+	id, err := strconv.Atoi(args[0])
+	if err != nil {
+		return fmt.Errorf("Invalid id: %s", args[0])
+	}
+
+	switch {
+	case command == "delete" && len(args) == 1:
+		return b.alerter.Delete(id)
+	case command == "rearm" && len(args) == 1:
+		return b.alerter.Rearm(id)
+	case command == "ack" && len(args) == 1:
+		return b.alerter.Ack(id)
+	case command == "snooze" && len(args) == 2:
+		d, err := time.ParseDuration(args[1])
+		if err != nil || d <= 0 {
+			return fmt.Errorf("Invalid duration: %s", args[1])
+		}
+		return b.alerter.Snooze(id, d)
+	}
+
+	return errUsage
+}
+
+func (b *Bot) alertCommand(args []string) (string, error) {
+	if len(args) == 0 {
+		return b.alerts(), nil
+	}
+
+	var id int
+	var err error
+
+	command, args := args[0], args[1:]
+
+	switch command {
+	case "price":
+		id, err = b.addPriceAlert(args)
+	case "move":
+		id, err = b.addMoveAlert(args)
+	case "funding":
+		if len(args) != 0 {
+			return "", errUsage
+		}
+		id = b.alerter.AddFundingAlert()
+	case "leverage", "exposure":
+		id, err = b.addThresholdAlert(command, args)
+	case "pnl":
+		id, err = b.addPortfolioAlert(alert.PnlMetric, args)
+	case "value":
+		id, err = b.addPortfolioAlert(alert.ValueMetric, args)
+	case "clear":
+		b.alerter.ClearAlerts()
+		return "Alerts cleared", nil
+	case "delete", "rearm", "ack", "snooze":
+		if err := b.manageAlert(command, args); err != nil {
+			return "", err
+		}
+		return "OK", nil
+	default:
+		return "", errUsage
+	}
+
+	if err != nil {
+		return "", err
+	}
+
+	log.WithFields(log.Fields{
+		"id":   id,
+		"type": command,
+	}).Info("Alert set from Telegram")
+
+	return fmt.Sprintf("Alert %d", id), nil
+}
+
+func commandName(field string) string {
+	return strings.ToLower(strings.SplitN(strings.TrimPrefix(field, "/"), "@", 2)[0])
+}
+
+func (b *Bot) ackReply(m *message) (string, error) {
+	id, ok := b.telegram.repliedTo(m)
+	if !ok {
+		return "", errUnknownReply
+	}
+
+	if err := b.alerter.Ack(id); err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("Alert %d acknowledged", id), nil
+}
+
+func (b *Bot) reply(m *message) (string, error) {
+	fields := strings.Fields(m.Text)
+
+	if m.ReplyToMessage != nil && len(fields) == 1 && commandName(fields[0]) == "ack" {
+		return b.ackReply(m)
+	}
+
+	if len(fields) == 0 || !strings.HasPrefix(fields[0], "/") {
+		return "", nil
+	}
+
+	command := commandName(fields[0])
+	args := fields[1:]
+
+	switch command {
+	case "start", "help":
+		return help, nil
+	case "pnl":
+		return b.pnl(), nil
+	case "assets":
+		return b.assets(), nil
+	case "prices":
+		return b.prices(), nil
+	case "exposure":
+		return b.exposure(), nil
+	case "leverage":
+		return b.leverage(), nil
+	case "funding":
+		return b.funding(), nil
+	case "feeds":
+		return b.feedStatus(), nil
+	case "alerts":
+		return b.alertCommand(args)
+	case "ack":
+		return b.alertCommand(append([]string{"ack"}, args...))
+	}
+
+	return "", errUsage
+}
+
+func (b *Bot) handle(m *message) {
+	if m == nil {
+		return
+	}
+
+	if m.Chat.Id != b.telegram.ChatId {
+		log.WithField("chat", m.Chat.Id).Warn("Ignoring Telegram message from unknown chat")
+		return
+	}
+
+	text, err := b.reply(m)
+	if err != nil {
+		text = err.Error()
+	}
+
+	if text == "" {
+		return
+	}
+
+	if _, err := b.telegram.send(text); err != nil {
+		log.Error(err.Error())
+	}
+}
+
+func (b *Bot) Run(ctx context.Context) {
+	offset := 0
+
+	for {
+		updates, err := b.telegram.updates(ctx, offset)
+		if ctx.Err() != nil {
+			return
+		}
+
+		if err != nil {
+			log.Error(err.Error())
+
+			select {
+			case <-time.After(5 * time.Second):
+			case <-ctx.Done():
+				return
+			}
+			continue
+		}
+
+		for _, u := range updates {
+			offset = u.UpdateId + 1
+			b.handle(u.Message)
+		}
+	}
+}
+
+func NewBot(t *Telegram, s *state.State, a *alert.Alerter, f *feed.Handler) *Bot {
+	return &Bot{
+		telegram: t,
+		state:    s,
+		alerter:  a,
+		feeds:    f}
+}
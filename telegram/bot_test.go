@@ -0,0 +1,162 @@
+package telegram
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stevenwilkin/treasury/alert"
+	"github.com/stevenwilkin/treasury/asset"
+	"github.com/stevenwilkin/treasury/state"
+	"github.com/stevenwilkin/treasury/symbol"
+	"github.com/stevenwilkin/treasury/venue"
+)
+
+type TestNotifier struct{}
+
+func (n *TestNotifier) Notify(_ alert.Alert) error { return nil }
+
+func testBot() *Bot {
+	s := state.NewState()
+	s.SetSymbol(symbol.BTCUSDT, 20000)
+	s.SetAsset(venue.Deribit, asset.BTC, 1.5)
+	s.SetFundingRate(0.0001)
+
+	return NewBot(&Telegram{ChatId: 1}, s, alert.NewAlerter(s, &TestNotifier{}), nil)
+}
+
+func command(text string) *message {
+	m := &message{Text: text}
+	m.Chat.Id = 1
+
+	return m
+}
+
+func TestBotCommands(t *testing.T) {
+	b := testBot()
+
+	tests := []struct {
+		text     string
+		expected string
+	}{
+		{"/prices", "BTCUSDT: 20000.000000"},
+		{"/assets", "Deribit\n  BTC: 1.50000000"},
+		{"/funding", "0.010000%"},
+		{"/exposure", "1.500000"},
+		{"/leverage", "No leverage"},
+		{"/feeds", "No feeds"},
+		{"/alerts", "No alerts"},
+		{"/pnl@treasury_bot", "Cost:  0.000000"},
+		{"/alerts leverage 4", "Alert 1"},
+		{"/alerts funding", "Alert 2"},
+		{"/alerts price 30000", "Alert 3"},
+		{"/alerts snooze 1 2h", "OK"},
+		{"/alerts delete 2", "OK"},
+		{"/alerts delete 2 3", errUsage.Error()},
+		{"/alerts price x 1", errUsage.Error()},
+		{"/alerts exposure 1 x", errUsage.Error()},
+		{"/unknown", errUsage.Error()}}
+
+	for _, test := range tests {
+		reply, err := b.reply(command(test.text))
+		if err != nil {
+			reply = err.Error()
+		}
+
+		if !strings.HasPrefix(reply, test.expected) {
+			t.Errorf("%s - Expected: '%s', got: '%s'", test.text, test.expected, reply)
+		}
+	}
+
+	if alerts := b.alerter.List(); len(alerts) != 2 || alerts[0].Snoozed.IsZero() {
+		t.Errorf("Unexpected alerts %+v", alerts)
+	}
+
+	if reply, _ := b.reply(command("/alerts clear")); reply != "Alerts cleared" {
+		t.Errorf("Expected: 'Alerts cleared', got: '%s'", reply)
+	}
+
+	if len(b.alerter.List()) != 0 {
+		t.Error("Should clear alerts")
+	}
+}
+
+func TestBotIgnoresText(t *testing.T) {
+	if reply, err := testBot().reply(command("hello")); reply != "" || err != nil {
+		t.Errorf("Should ignore text, got '%s' %v", reply, err)
+	}
+}
+
+func TestBotReplies(t *testing.T) {
+	b := testBot()
+	b.telegram.remember(5, b.alerter.AddFundingAlert())
+
+	tests := []struct {
+		message  *message
+		expected string
+	}{
+		{reply(1, 5, "/pnl").Message, "Cost:  0.000000"},
+		{reply(1, 5, "ok").Message, ""},
+		{reply(1, 6, "ack").Message, errUnknownReply.Error()},
+		{reply(1, 5, "/ack").Message, alert.ErrNotEscalating.Error()}}
+
+	for _, test := range tests {
+		reply, err := b.reply(test.message)
+		if err != nil {
+			reply = err.Error()
+		}
+
+		if !strings.HasPrefix(reply, test.expected) || (test.expected == "" && reply != "") {
+			t.Errorf("%s - Expected: '%s', got: '%s'", test.message.Text, test.expected, reply)
+		}
+	}
+}
+
+func TestBotAcknowledgesReply(t *testing.T) {
+	ts := &testServer{}
+	server := newServer(t, ts)
+
+	telegram := &Telegram{ApiUrl: server, ChatId: 1}
+	escalator := alert.NewEscalator(&TestNotifier{}, []alert.Step{
+		{Notifier: telegram},
+		{Notifier: &TestNotifier{}, After: time.Hour}})
+
+	s := state.NewState()
+	s.SetFundingRate(-0.01)
+	alerter := alert.NewAlerter(s, escalator)
+	id := alerter.AddFundingAlert()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go alerter.Run(ctx)
+	alerter.CheckAlerts()
+
+	for i := 0; len(ts.messages()) == 0 && i < 100; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if !alerter.List()[0].Escalating {
+		t.Fatal("Should be escalating")
+	}
+
+	ts.mu.Lock()
+	ts.updates = []update{reply(2, 1, "ack"), reply(1, 1, "ack")}
+	ts.mu.Unlock()
+
+	go NewBot(telegram, s, alerter, nil).Run(ctx)
+
+	for i := 0; len(ts.messages()) < 2 && i < 100; i++ {
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	sent := ts.messages()
+	if len(sent) != 2 || sent[1] != fmt.Sprintf("Alert %d acknowledged", id) {
+		t.Errorf("Should acknowledge only from configured chat, got %v", sent)
+	}
+
+	if alerter.List()[0].Escalating {
+		t.Error("Should stop escalating")
+	}
+}
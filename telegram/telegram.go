@@ -2,6 +2,7 @@ package telegram
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -9,15 +10,26 @@ import (
 	"net/http"
 	"os"
 	"strconv"
+	"sync"
 
 	"github.com/stevenwilkin/treasury/alert"
 
 	log "github.com/sirupsen/logrus"
 )
 
+const (
+	defaultApiUrl = "https://api.telegram.org"
+	pollTimeout   = 30
+	maxSent       = 100
+)
+
 type Telegram struct {
+	ApiUrl   string
 	ApiToken string
 	ChatId   int
+	mu       sync.Mutex
+	sent     map[int]int
+	order    []int
 }
 
 type sendMessageParams struct {
@@ -25,20 +37,47 @@ type sendMessageParams struct {
 	Text   string `json:"text"`
 }
 
+type message struct {
+	MessageId int `json:"message_id"`
+	Chat      struct {
+		Id int `json:"id"`
+	} `json:"chat"`
+	Text           string   `json:"text"`
+	ReplyToMessage *message `json:"reply_to_message"`
+}
+
+type update struct {
+	UpdateId int      `json:"update_id"`
+	Message  *message `json:"message"`
+}
+
 type sendMessageResponse struct {
-	Ok bool
+	Ok     bool
+	Result message
 }
 
-func (t *Telegram) Notify(a alert.Alert) error {
-	url := fmt.Sprintf("https://api.telegram.org/bot%s/sendMessage", t.ApiToken)
+type getUpdatesResponse struct {
+	Ok     bool
+	Result []update
+}
+
+func (t *Telegram) url(method string) string {
+	apiUrl := t.ApiUrl
+	if apiUrl == "" {
+		apiUrl = defaultApiUrl
+	}
+
+	return fmt.Sprintf("%s/bot%s/%s", apiUrl, t.ApiToken, method)
+}
 
-	params := sendMessageParams{ChatId: t.ChatId, Text: a.Message()}
+func (t *Telegram) call(ctx context.Context, method string, params, result interface{}) error {
 	jsonParams, err := json.Marshal(params)
 	if err != nil {
 		return err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewBuffer(jsonParams))
+	req, err := http.NewRequestWithContext(
+		ctx, "POST", t.url(method), bytes.NewBuffer(jsonParams))
 	if err != nil {
 		return err
 	}
@@ -58,16 +97,83 @@ func (t *Telegram) Notify(a alert.Alert) error {
 		return err
 	}
 
+	return json.Unmarshal(body, result)
+}
+
+func (t *Telegram) send(text string) (int, error) {
+	params := sendMessageParams{ChatId: t.ChatId, Text: text}
+
 	var response sendMessageResponse
-	json.Unmarshal(body, &response)
+	t.call(context.Background(), "sendMessage", params, &response)
 
 	if response.Ok {
-		return nil
+		return response.Result.MessageId, nil
 	} else {
-		return errors.New("Error sending message")
+		return 0, errors.New("Error sending message")
 	}
 }
 
+func (t *Telegram) remember(messageId, alertId int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.sent == nil {
+		t.sent = map[int]int{}
+	}
+
+	t.sent[messageId] = alertId
+	t.order = append(t.order, messageId)
+
+	if len(t.order) > maxSent {
+		delete(t.sent, t.order[0])
+		t.order = t.order[1:]
+	}
+}
+
+func (t *Telegram) Notify(a alert.Alert) error {
+	messageId, err := t.send(a.Message())
+	if err != nil {
+		return err
+	}
+
+	if id := alert.AlertId(a); id > 0 {
+		t.remember(messageId, id)
+	}
+
+	return nil
+}
+
+func (t *Telegram) updates(ctx context.Context, offset int) ([]update, error) {
+	params := map[string]int{"offset": offset, "timeout": pollTimeout}
+
+	var response getUpdatesResponse
+	if err := t.call(ctx, "getUpdates", params, &response); err != nil {
+		return nil, err
+	}
+
+	if !response.Ok {
+		return nil, errors.New("Error getting updates")
+	}
+
+	return response.Result, nil
+}
+
+func (t *Telegram) repliedTo(m *message) (int, bool) {
+	if m.ReplyToMessage == nil {
+		return 0, false
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	id, ok := t.sent[m.ReplyToMessage.MessageId]
+	return id, ok
+}
+
+func (t *Telegram) Configured() bool {
+	return t.ApiToken != "" && t.ChatId != 0
+}
+
 func NewFromEnv() *Telegram {
 	chatId, err := strconv.Atoi(os.Getenv("TELEGRAM_CHAT_ID"))
 	if err != nil {
@@ -0,0 +1,86 @@
+package telegram
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stevenwilkin/treasury/alert"
+)
+
+type testServer struct {
+	mu      sync.Mutex
+	sent    []string
+	updates []update
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	body, _ := ioutil.ReadAll(r.Body)
+
+	switch {
+	case strings.HasSuffix(r.URL.Path, "/sendMessage"):
+		var params sendMessageParams
+		json.Unmarshal(body, &params)
+		s.sent = append(s.sent, params.Text)
+
+		response := sendMessageResponse{Ok: true}
+		response.Result.MessageId = len(s.sent)
+		json.NewEncoder(w).Encode(response)
+	case strings.HasSuffix(r.URL.Path, "/getUpdates"):
+		json.NewEncoder(w).Encode(getUpdatesResponse{Ok: true, Result: s.updates})
+		s.updates = nil
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func (s *testServer) messages() []string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return append([]string{}, s.sent...)
+}
+
+type testAlert struct {
+	alert.Alert
+	id int
+}
+
+func (a *testAlert) AlertId() int    { return a.id }
+func (a *testAlert) Message() string { return "Funding alert" }
+
+func reply(chatId, replyTo int, text string) update {
+	m := &message{Text: text}
+	m.Chat.Id = chatId
+	if replyTo > 0 {
+		m.ReplyToMessage = &message{MessageId: replyTo}
+	}
+
+	return update{Message: m}
+}
+
+func newServer(t *testing.T, ts *testServer) string {
+	server := httptest.NewServer(ts)
+	t.Cleanup(server.Close)
+
+	return server.URL
+}
+
+func TestNotify(t *testing.T) {
+	ts := &testServer{}
+	tg := &Telegram{ApiUrl: newServer(t, ts), ChatId: 1}
+	if err := tg.Notify(&testAlert{id: 3}); err != nil {
+		t.Fatal(err)
+	}
+
+	if sent := ts.messages(); len(sent) != 1 || sent[0] != "Funding alert" {
+		t.Errorf("Unexpected messages %v", sent)
+	}
+}
@@ -1,7 +1,9 @@
 package twilio
 
 import (
+	"bytes"
 	"encoding/json"
+	"encoding/xml"
 	"errors"
 	"fmt"
 	"io/ioutil"
@@ -13,29 +15,53 @@ import (
 	"github.com/stevenwilkin/treasury/alert"
 )
 
+const defaultApiUrl = "https://api.twilio.com"
+
 type Twilio struct {
+	ApiUrl     string
 	AccountSid string
 	AuthToken  string
 	From       string
-	To         string
+	To         []string
+	Sms        bool
+	Voice      string
+	Loop       int
 }
 
 type errorResponse struct {
 	Message string `json:"message"`
 }
 
-func (t *Twilio) Notify(_ alert.Alert) error {
-	v := url.Values{
-		"Twiml": {"<Response><Say>Alert</Say></Response>"},
-		"From":  {t.From},
-		"To":    {t.To}}
+func (t *Twilio) twiml(message string) string {
+	if message == "" {
+		message = "Alert"
+	}
+
+	var b bytes.Buffer
+	xml.EscapeText(&b, []byte(message))
+
+	attributes := ""
+	if t.Voice != "" {
+		var voice bytes.Buffer
+		xml.EscapeText(&voice, []byte(t.Voice))
+		attributes += fmt.Sprintf(` voice="%s"`, voice.String())
+	}
+	if t.Loop > 0 {
+		attributes += fmt.Sprintf(` loop="%d"`, t.Loop)
+	}
 
-	u := url.URL{
-		Scheme: "https",
-		Host:   "api.twilio.com",
-		Path:   fmt.Sprintf("/2010-04-01/Accounts/%s/Calls.json", t.AccountSid)}
+	return fmt.Sprintf("<Response><Say%s>%s</Say></Response>", attributes, b.String())
+}
+
+func (t *Twilio) post(resource string, v url.Values) error {
+	apiUrl := t.ApiUrl
+	if apiUrl == "" {
+		apiUrl = defaultApiUrl
+	}
+
+	u := fmt.Sprintf("%s/2010-04-01/Accounts/%s/%s.json", apiUrl, t.AccountSid, resource)
 
-	req, err := http.NewRequest("POST", u.String(), strings.NewReader(v.Encode()))
+	req, err := http.NewRequest("POST", u, strings.NewReader(v.Encode()))
 	if err != nil {
 		return err
 	}
@@ -62,15 +88,59 @@ func (t *Twilio) Notify(_ alert.Alert) error {
 	var response errorResponse
 	json.Unmarshal(body, &response)
 
+	if response.Message == "" {
+		return fmt.Errorf("Unexpected status %d", resp.StatusCode)
+	}
+
 	return errors.New(response.Message)
 }
 
+func (t *Twilio) notify(to string, a alert.Alert) error {
+	if t.Sms {
+		return t.post("Messages", url.Values{
+			"Body": {a.Message()},
+			"From": {t.From},
+			"To":   {to}})
+	}
+
+	return t.post("Calls", url.Values{
+		"Twiml": {t.twiml(a.Message())},
+		"From":  {t.From},
+		"To":    {to}})
+}
+
+func (t *Twilio) Notify(a alert.Alert) error {
+	if len(t.To) == 0 {
+		return errors.New("No recipients")
+	}
+
+	failed := []string{}
+	for _, to := range t.To {
+		if err := t.notify(to, a); err != nil {
+			failed = append(failed, fmt.Sprintf("%s: %s", to, err.Error()))
+		}
+	}
+
+	if len(failed) > 0 {
+		return errors.New(strings.Join(failed, ", "))
+	}
+
+	return nil
+}
+
 func NewFromEnv() *Twilio {
+	to := []string{}
+	for _, recipient := range strings.Split(os.Getenv("TWILIO_TO"), ",") {
+		if recipient = strings.TrimSpace(recipient); recipient != "" {
+			to = append(to, recipient)
+		}
+	}
+
 	return &Twilio{
 		AccountSid: os.Getenv("TWILIO_ACCOUNT_SID"),
 		AuthToken:  os.Getenv("TWILIO_AUTH_TOKEN"),
 		From:       os.Getenv("TWILIO_FROM"),
-		To:         os.Getenv("TWILIO_TO")}
+		To:         to}
 }
 
 var _ alert.Notifier = &Twilio{}
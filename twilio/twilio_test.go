@@ -0,0 +1,134 @@
+package twilio
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/stevenwilkin/treasury/alert"
+)
+
+type request struct {
+	path   string
+	values url.Values
+}
+
+type testServer struct {
+	mu       sync.Mutex
+	requests []request
+	fail     string
+}
+
+func (s *testServer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if user, pass, ok := r.BasicAuth(); !ok || user != "sid" || pass != "token" {
+		w.WriteHeader(http.StatusUnauthorized)
+		return
+	}
+
+	r.ParseForm()
+	s.requests = append(s.requests, request{path: r.URL.Path, values: r.PostForm})
+
+	if r.PostForm.Get("To") == s.fail {
+		w.WriteHeader(http.StatusBadRequest)
+		w.Write([]byte(`{"message": "Invalid number"}`))
+		return
+	}
+
+	w.WriteHeader(http.StatusCreated)
+}
+
+type testAlert struct {
+	alert.Alert
+}
+
+func (a *testAlert) Message() string { return "BTC < 20000 & falling" }
+
+func testTwilio(t *testing.T, ts *testServer) *Twilio {
+	server := httptest.NewServer(ts)
+	t.Cleanup(server.Close)
+
+	return &Twilio{
+		ApiUrl:     server.URL,
+		AccountSid: "sid",
+		AuthToken:  "token",
+		From:       "+100",
+		To:         []string{"+200"}}
+}
+
+func TestCall(t *testing.T) {
+	ts := &testServer{}
+	tw := testTwilio(t, ts)
+	tw.Voice = "alice"
+	tw.Loop = 2
+
+	if err := tw.Notify(&testAlert{}); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(ts.requests) != 1 {
+		t.Fatalf("Expected 1 request, got %d", len(ts.requests))
+	}
+
+	r := ts.requests[0]
+	if r.path != "/2010-04-01/Accounts/sid/Calls.json" {
+		t.Errorf("Unexpected path %s", r.path)
+	}
+
+	expected := `<Response><Say voice="alice" loop="2">BTC &lt; 20000 &amp; falling</Say></Response>`
+	if twiml := r.values.Get("Twiml"); twiml != expected {
+		t.Errorf("Expected: '%s', got: '%s'", expected, twiml)
+	}
+
+	if r.values.Get("From") != "+100" || r.values.Get("To") != "+200" {
+		t.Errorf("Unexpected numbers %v", r.values)
+	}
+}
+
+func TestSms(t *testing.T) {
+	ts := &testServer{}
+	tw := testTwilio(t, ts)
+	tw.Sms = true
+
+	if err := tw.Notify(&testAlert{}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := ts.requests[0]
+	if r.path != "/2010-04-01/Accounts/sid/Messages.json" {
+		t.Errorf("Unexpected path %s", r.path)
+	}
+
+	if body := r.values.Get("Body"); body != "BTC < 20000 & falling" {
+		t.Errorf("Expected: '%s', got: '%s'", "BTC < 20000 & falling", body)
+	}
+}
+
+func TestMultipleRecipients(t *testing.T) {
+	ts := &testServer{fail: "+300"}
+	tw := testTwilio(t, ts)
+	tw.To = []string{"+200", "+300", "+400"}
+
+	err := tw.Notify(&testAlert{})
+	if err == nil || !strings.Contains(err.Error(), "+300: Invalid number") {
+		t.Errorf("Should report failed recipient, got %v", err)
+	}
+
+	if len(ts.requests) != 3 {
+		t.Errorf("Should notify every recipient, got %d requests", len(ts.requests))
+	}
+}
+
+func TestNoRecipients(t *testing.T) {
+	tw := testTwilio(t, &testServer{})
+	tw.To = nil
+
+	if err := tw.Notify(&testAlert{}); err == nil {
+		t.Error("Should return an error without recipients")
+	}
+}
@@ -0,0 +1,37 @@
+package venue
+
+import (
+	"context"
+
+	"github.com/stevenwilkin/treasury/asset"
+	"github.com/stevenwilkin/treasury/symbol"
+)
+
+type BalanceProvider interface {
+	GetBalances() (asset.Balances, error)
+}
+
+type BalanceStreamer interface {
+	Balances(context.Context, func(error)) chan asset.Balances
+}
+
+type PriceProvider interface {
+	GetPrice(symbol.Symbol) (float64, error)
+}
+
+type PriceStreamer interface {
+	Price(context.Context, symbol.Symbol, func(error)) chan float64
+}
+
+type PositionProvider interface {
+	GetSize() int
+	GetLeverage() (float64, error)
+}
+
+type AccountProvider interface {
+	GetAccount() (asset.Balances, float64, error)
+}
+
+type FundingProvider interface {
+	GetFundingRate() (float64, error)
+}
@@ -15,10 +15,13 @@ const (
 	Ledn
 	Loan
 	Ledger
+	Bitkub
+	XE
 )
 
 func venues() []string {
-	return []string{"Nexo", "Deribit", "Bybit", "Binance", "Ledn", "Loan", "Ledger"}
+	return []string{
+		"Nexo", "Deribit", "Bybit", "Binance", "Ledn", "Loan", "Ledger", "Bitkub", "XE"}
 }
 
 func (v Venue) String() string {
@@ -1,6 +1,10 @@
 package venue
 
-import "testing"
+import (
+	"testing"
+
+	"github.com/stevenwilkin/treasury/config"
+)
 
 func TestVenueToString(t *testing.T) {
 	tests := map[Venue]string{Nexo: "Nexo", Binance: "Binance"}
@@ -28,3 +32,30 @@ func TestFromStringValidVenue(t *testing.T) {
 		t.Errorf("Unexpected venue %s", v)
 	}
 }
+
+func TestNewRegistry(t *testing.T) {
+	r := NewRegistry(config.Default())
+
+	if len(r) != 5 {
+		t.Errorf("Expected 5 venues, got %d", len(r))
+	}
+
+	pp := r.PositionProviders()
+	if len(pp) != 2 {
+		t.Fatalf("Expected 2 position providers, got %d", len(pp))
+	}
+
+	if _, ok := pp[Deribit]; !ok {
+		t.Error("Deribit should be a position provider")
+	}
+}
+
+func TestNewRegistryWithDisabledVenue(t *testing.T) {
+	c := config.Default()
+	disabled := false
+	c.Venues = map[string]config.Venue{"bybit": {Enabled: &disabled}}
+
+	if _, ok := NewRegistry(c)[Bybit]; ok {
+		t.Error("Should not contain disabled venue")
+	}
+}
@@ -1,37 +1,81 @@
 package venue
 
 import (
-	"os"
+	"strings"
 
 	"github.com/stevenwilkin/treasury/binance"
 	"github.com/stevenwilkin/treasury/bitkub"
 	"github.com/stevenwilkin/treasury/bybit"
+	"github.com/stevenwilkin/treasury/config"
 	"github.com/stevenwilkin/treasury/deribit"
 	"github.com/stevenwilkin/treasury/xe"
 )
 
-type Venues struct {
-	Binance *binance.Binance
-	Bitkub  *bitkub.Bitkub
-	Deribit *deribit.Deribit
-	Bybit   *bybit.Bybit
-	XE      *xe.XE
+type Client struct {
+	BalanceProvider  BalanceProvider
+	BalanceStreamer  BalanceStreamer
+	PriceProvider    PriceProvider
+	PriceStreamer    PriceStreamer
+	PositionProvider PositionProvider
+	AccountProvider  AccountProvider
+	FundingProvider  FundingProvider
 }
 
-func NewVenues() Venues {
-	venues := Venues{}
-
-	venues.Binance = &binance.Binance{
-		ApiKey:    os.Getenv("BINANCE_API_KEY"),
-		ApiSecret: os.Getenv("BINANCE_API_SECRET")}
-	venues.Bitkub = &bitkub.Bitkub{}
-	venues.Deribit = &deribit.Deribit{
-		ApiId:     os.Getenv("DERIBIT_API_ID"),
-		ApiSecret: os.Getenv("DERIBIT_API_SECRET")}
-	venues.Bybit = &bybit.Bybit{
-		ApiKey:    os.Getenv("BYBIT_API_KEY"),
-		ApiSecret: os.Getenv("BYBIT_API_SECRET")}
-	venues.XE = &xe.XE{}
-
-	return venues
+type Registry map[Venue]Client
+
+func (r Registry) PositionProviders() map[Venue]PositionProvider {
+	results := map[Venue]PositionProvider{}
+
+	for v, client := range r {
+		if client.PositionProvider != nil {
+			results[v] = client.PositionProvider
+		}
+	}
+
+	return results
+}
+
+func NewRegistry(c *config.Config) Registry {
+	clients := map[Venue]func() Client{
+		Binance: func() Client {
+			key, secret := c.VenueCredentials(config.Binance)
+			b := &binance.Binance{
+				ApiKey:    key,
+				ApiSecret: secret,
+				Testnet:   c.Venue(config.Binance).Testnet}
+			return Client{BalanceProvider: b, PriceStreamer: b}
+		},
+		Bitkub: func() Client {
+			b := &bitkub.Bitkub{}
+			return Client{PriceStreamer: b, PriceProvider: b}
+		},
+		Deribit: func() Client {
+			id, secret := c.VenueCredentials(config.Deribit)
+			d := &deribit.Deribit{
+				ApiId:     id,
+				ApiSecret: secret,
+				Test:      c.Venue(config.Deribit).Testnet}
+			return Client{BalanceStreamer: d, PositionProvider: d}
+		},
+		Bybit: func() Client {
+			key, secret := c.VenueCredentials(config.Bybit)
+			b := &bybit.Bybit{
+				ApiKey:    key,
+				ApiSecret: secret,
+				Testnet:   c.Venue(config.Bybit).Testnet}
+			return Client{AccountProvider: b, PositionProvider: b, FundingProvider: b}
+		},
+		XE: func() Client {
+			return Client{PriceProvider: &xe.XE{}}
+		}}
+
+	r := Registry{}
+
+	for v, client := range clients {
+		if c.VenueEnabled(strings.ToLower(v.String())) {
+			r[v] = client()
+		}
+	}
+
+	return r
 }
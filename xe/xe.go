@@ -10,6 +10,8 @@ import (
 	"regexp"
 	"time"
 
+	"github.com/stevenwilkin/treasury/symbol"
+
 	log "github.com/sirupsen/logrus"
 )
 
@@ -70,7 +72,7 @@ func (x *XE) accessToken() (string, error) {
 
 }
 
-func (x *XE) GetPrice() (float64, error) {
+func (x *XE) GetPrice(s symbol.Symbol) (float64, error) {
 	var err error
 	defer func() {
 		if err != nil {
@@ -78,6 +80,11 @@ func (x *XE) GetPrice() (float64, error) {
 		}
 	}()
 
+	if s != symbol.USDTHB {
+		err = fmt.Errorf("Unsupported symbol: %s", s)
+		return 0, err
+	}
+
 	accessToken, err := x.accessToken()
 	if err != nil {
 		return 0, err